@@ -3,11 +3,16 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
 	"github.com/VanVodkaer/LawConnect-API/internal/db"
 	"github.com/VanVodkaer/LawConnect-API/internal/router"
 	"github.com/VanVodkaer/LawConnect-API/utils/admin"
 	"github.com/VanVodkaer/LawConnect-API/utils/config"
+	"github.com/VanVodkaer/LawConnect-API/utils/filter"
+	"github.com/VanVodkaer/LawConnect-API/utils/mail"
+	"github.com/VanVodkaer/LawConnect-API/utils/region"
 )
 
 func main() {
@@ -20,6 +25,18 @@ func main() {
 	// 创建管理员账户（如果不存在）
 	admin.CreateAdminIfNotExists()
 
+	// 导入行政区划
+	initRegions()
+
+	// 加载敏感词库
+	initFilter()
+
+	// 初始化签名密钥、令牌服务和令牌吊销存储
+	initAuth()
+
+	// 初始化邮件发送
+	initMail()
+
 	// 初始化并启动服务器
 	initServer()
 }
@@ -38,6 +55,88 @@ func initDB() {
 	db.InitDB(dsn)
 }
 
+// initRegions 从 GB/T 2260 行政区划代码文件导入行政区划
+func initRegions() {
+	regions, err := region.LoadFile(config.GlobalConfig.Regions.File)
+	if err != nil {
+		log.Fatal("读取行政区划代码失败: ", err)
+	}
+	if err := db.ImportRegions(regions); err != nil {
+		log.Fatal("导入行政区划失败: ", err)
+	}
+	log.Printf("已导入 %d 个行政区划", len(regions))
+}
+
+// initFilter 加载敏感词库并按配置定期重新加载
+func initFilter() {
+	rules, err := db.GetFilterRules()
+	if err != nil {
+		log.Fatal("加载敏感词库失败: ", err)
+	}
+	filter.Reload(rules)
+
+	interval := time.Duration(config.GlobalConfig.Filter.ReloadInterval) * time.Second
+	filter.StartAutoReload(interval, db.GetFilterRules)
+}
+
+// initAuth 初始化签名密钥、令牌服务、登录防护和令牌吊销存储
+func initAuth() {
+	var jwtConfig = config.GlobalConfig.JWT
+
+	var mailConfig = config.GlobalConfig.Mail
+	verifyTTL := time.Duration(mailConfig.VerifyExpire) * time.Second
+	resetTTL := time.Duration(mailConfig.ResetExpire) * time.Second
+
+	// 旧密钥退役后需保留到其签发的访问令牌和邮件令牌全部过期
+	rotate := time.Duration(jwtConfig.RotateInterval) * time.Hour
+	retention := time.Duration(jwtConfig.Expire) * time.Second
+	for _, ttl := range []time.Duration{verifyTTL, resetTTL} {
+		if ttl > retention {
+			retention = ttl
+		}
+	}
+	if err := auth.InitKeys(jwtConfig.Algorithm, jwtConfig.Secret, jwtConfig.KeyDir, rotate, retention); err != nil {
+		log.Fatal("初始化签名密钥失败: ", err)
+	}
+
+	auth.InitTokens(auth.TokenOptions{
+		Issuer:     jwtConfig.Issuer,
+		Audience:   jwtConfig.Audience,
+		AccessTTL:  time.Duration(jwtConfig.Expire) * time.Second,
+		RefreshTTL: time.Duration(jwtConfig.RefreshExpire) * time.Second,
+		VerifyTTL:  verifyTTL,
+		ResetTTL:   resetTTL,
+	})
+
+	var loginConfig = config.GlobalConfig.Login
+	lockBase := time.Duration(loginConfig.LockoutBase) * time.Second
+	lockMax := time.Duration(loginConfig.LockoutMax) * time.Second
+	auth.InitLoginGuard(auth.LoginGuardOptions{
+		Account: auth.LockoutPolicy{Threshold: loginConfig.MaxAccountFailures, Base: lockBase, Max: lockMax},
+		IP:      auth.LockoutPolicy{Threshold: loginConfig.MaxIPFailures, Base: lockBase, Max: lockMax},
+		Window:  time.Duration(loginConfig.FailureWindow) * time.Second,
+	})
+
+	auth.InitRevocationStore(jwtConfig.RevocationStore, time.Duration(jwtConfig.CleanupInterval)*time.Second)
+}
+
+// initMail 初始化邮件发送器
+func initMail() {
+	var mailConfig = config.GlobalConfig.Mail
+	err := mail.Init(mail.Options{
+		Driver:   mailConfig.Driver,
+		From:     mailConfig.From,
+		Host:     mailConfig.Host,
+		Port:     mailConfig.Port,
+		Username: mailConfig.Username,
+		Password: mailConfig.Password,
+		Path:     mailConfig.Path,
+	})
+	if err != nil {
+		log.Fatal("初始化邮件发送失败: ", err)
+	}
+}
+
 // initServer 初始化并启动服务器
 func initServer() {
 	var server = config.GlobalConfig.Server
@@ -0,0 +1,395 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256" // HMAC 共享密钥
+	AlgRS256 = "RS256" // RSA 2048
+	AlgES256 = "ES256" // ECDSA P-256
+	AlgEdDSA = "EdDSA" // Ed25519
+)
+
+// rsaKeyBits 生成 RSA 密钥的位数
+const rsaKeyBits = 2048
+
+// kidTimeLayout kid 中创建时间前缀的格式（UTC）
+const kidTimeLayout = "20060102T150405"
+
+// createdAtHeader PEM 头部中记录密钥创建时间的字段，不依赖文件修改时间
+const createdAtHeader = "Created-At"
+
+// minReloadInterval 遇到未知 kid 时重新加载密钥目录的最短间隔，避免伪造 kid 频繁触发读盘
+const minReloadInterval = 10 * time.Second
+
+// SigningKey 一把签名密钥
+type SigningKey struct {
+	Kid       string
+	Alg       string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	CreatedAt time.Time
+}
+
+// KeySet 签名密钥集合：最新的密钥用于签发，轮换下来的旧密钥在保留期内仍可用于验证
+type KeySet struct {
+	mu        sync.RWMutex
+	alg       string
+	secret    []byte
+	dir       string
+	rotate    time.Duration // 密钥轮换周期
+	retention time.Duration // 旧密钥退役后继续用于验证的时长（不短于访问令牌有效期）
+	active    *SigningKey
+	keys      map[string]*SigningKey
+
+	reloadMu   sync.Mutex
+	lastReload time.Time // 上次因未知 kid 重新加载密钥目录的时间
+}
+
+// Keys 当前使用的密钥集合，启动时由 InitKeys 设置
+var Keys *KeySet
+
+// InitKeys 初始化密钥集合：HS256 使用共享密钥，其他算法从 dir 加载 PEM 私钥，必要时生成新密钥
+// rotate 大于 0 时按周期轮换密钥，多个实例可共享同一个密钥目录
+func InitKeys(alg, secret, dir string, rotate, retention time.Duration) error {
+	ks := &KeySet{
+		alg:       alg,
+		secret:    []byte(secret),
+		dir:       dir,
+		rotate:    rotate,
+		retention: retention,
+		keys:      make(map[string]*SigningKey),
+	}
+	if alg == "" {
+		ks.alg = AlgHS256
+	}
+
+	switch ks.alg {
+	case AlgHS256:
+	case AlgRS256, AlgES256, AlgEdDSA:
+		if err := ks.Rotate(); err != nil {
+			return err
+		}
+		ks.startRotation()
+	default:
+		return fmt.Errorf("不支持的签名算法: %s", alg)
+	}
+
+	Keys = ks
+	return nil
+}
+
+// Sign 使用当前密钥签发令牌，非对称算法会在头部写入 kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if ks.alg == AlgHS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+	if ks.active == nil {
+		return "", errors.New("没有可用的签名密钥")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.active.Alg), claims)
+	token.Header["kid"] = ks.active.Kid
+	return token.SignedString(ks.active.Private)
+}
+
+// Keyfunc 供 jwt.Parse 使用：按 kid 选择验证密钥，并确保签名算法与密钥一致
+// 遇到未知 kid 时重新加载一次密钥目录，以识别其他实例刚生成的密钥
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if ks.alg == AlgHS256 {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("无效的签名方法")
+		}
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.lookup(kid)
+	if !ok && kid != "" && ks.reload() {
+		key, ok = ks.lookup(kid)
+	}
+	if !ok {
+		return nil, errors.New("未知的签名密钥")
+	}
+	if token.Method.Alg() != key.Alg {
+		return nil, errors.New("无效的签名方法")
+	}
+	return key.Public, nil
+}
+
+// lookup 按 kid 查找验证密钥
+func (ks *KeySet) lookup(kid string) (*SigningKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// reload 重新加载密钥目录并加入尚未加载的密钥，不生成或清理密钥，也不切换签名密钥
+// 距上次重新加载不足 minReloadInterval 时直接返回 false
+func (ks *KeySet) reload() bool {
+	ks.reloadMu.Lock()
+	defer ks.reloadMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(ks.lastReload) < minReloadInterval {
+		return false
+	}
+	ks.lastReload = now
+
+	keys, err := loadKeys(ks.dir)
+	if err != nil {
+		log.Printf("重新加载签名密钥失败: %v", err)
+		return false
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := ks.keys[key.Kid]; !ok {
+			ks.keys[key.Kid] = key
+		}
+	}
+	return true
+}
+
+// PublicKeys 返回当前可用于验证的公钥，按创建时间从新到旧排列
+func (ks *KeySet) PublicKeys() []*SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	keys := make([]*SigningKey, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// Rotate 重新加载密钥目录：当前算法没有密钥或最新密钥已超过轮换周期时生成新密钥，
+// 并清理退役时间超过保留期的旧密钥
+func (ks *KeySet) Rotate() error {
+	if err := os.MkdirAll(ks.dir, 0o700); err != nil {
+		return fmt.Errorf("创建密钥目录失败: %v", err)
+	}
+
+	keys, err := loadKeys(ks.dir)
+	if err != nil {
+		return err
+	}
+
+	// 最新的当前算法密钥作为签名密钥
+	now := time.Now()
+	var active *SigningKey
+	for _, key := range keys {
+		if key.Alg == ks.alg {
+			active = key
+		}
+	}
+	if active == nil || (ks.rotate > 0 && now.Sub(active.CreatedAt) >= ks.rotate) {
+		if active, err = generateKey(ks.dir, ks.alg, now); err != nil {
+			return err
+		}
+		keys = append(keys, active)
+		log.Printf("已生成新的签名密钥: %s (%s)", active.Kid, active.Alg)
+	}
+
+	// 每把密钥在下一把密钥创建时退役，退役超过保留期后删除
+	kept := make(map[string]*SigningKey)
+	for i, key := range keys {
+		if key != active && i+1 < len(keys) && now.Sub(keys[i+1].CreatedAt) > ks.retention {
+			if err := os.Remove(filepath.Join(ks.dir, key.Kid+".pem")); err != nil {
+				log.Printf("删除过期签名密钥失败: %v", err)
+			}
+			continue
+		}
+		kept[key.Kid] = key
+	}
+
+	ks.mu.Lock()
+	ks.active = active
+	ks.keys = kept
+	ks.mu.Unlock()
+	return nil
+}
+
+// startRotation 定期检查并轮换密钥，同时同步其他实例生成的密钥
+func (ks *KeySet) startRotation() {
+	if ks.rotate <= 0 {
+		return
+	}
+
+	// 检查间隔取轮换周期的十分之一，最长一小时
+	interval := ks.rotate / 10
+	if interval > time.Hour {
+		interval = time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := ks.Rotate(); err != nil {
+				log.Printf("轮换签名密钥失败: %v", err)
+			}
+		}
+	}()
+}
+
+// loadKeys 从目录加载全部 PEM 私钥，按创建时间从旧到新排列
+func loadKeys(dir string) ([]*SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取签名密钥失败: %v", err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("签名密钥格式不正确: %s", file)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("解析签名密钥失败: %s: %v", file, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("不支持的签名密钥类型: %s", file)
+		}
+		alg, err := keyAlgorithm(signer)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s", err, file)
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		createdAt, err := keyCreatedAt(block, kid, file)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &SigningKey{
+			Kid:       kid,
+			Alg:       alg,
+			Private:   signer,
+			Public:    signer.Public(),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// keyCreatedAt 确定密钥的创建时间：优先读取 PEM 头部，其次解析 kid 中的时间前缀，
+// 手动放入目录且两者都没有的密钥才使用文件修改时间
+func keyCreatedAt(block *pem.Block, kid, file string) (time.Time, error) {
+	if v, ok := block.Headers[createdAtHeader]; ok {
+		createdAt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("签名密钥创建时间格式不正确: %s", file)
+		}
+		return createdAt, nil
+	}
+	if prefix, _, ok := strings.Cut(kid, "-"); ok {
+		if createdAt, err := time.Parse(kidTimeLayout, prefix); err == nil {
+			return createdAt, nil
+		}
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// generateKey 生成指定算法的新密钥并以 PKCS#8 PEM 格式保存到目录
+func generateKey(dir, alg string, now time.Time) (*SigningKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	// kid 由创建时间和随机后缀组成，避免多实例同时生成时冲突
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	kid := now.UTC().Format(kidTimeLayout) + "-" + hex.EncodeToString(suffix)
+
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdAtHeader: now.UTC().Format(time.RFC3339Nano)},
+		Bytes:   der,
+	})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		return nil, fmt.Errorf("保存签名密钥失败: %v", err)
+	}
+
+	return &SigningKey{Kid: kid, Alg: alg, Private: signer, Public: signer.Public(), CreatedAt: now}, nil
+}
+
+// keyAlgorithm 根据私钥类型确定签名算法
+func keyAlgorithm(signer crypto.Signer) (string, error) {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		return AlgRS256, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return "", errors.New("仅支持 P-256 曲线的 ECDSA 密钥")
+		}
+		return AlgES256, nil
+	case ed25519.PrivateKey:
+		return AlgEdDSA, nil
+	default:
+		return "", errors.New("不支持的签名密钥类型")
+	}
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// newTestKeys 在临时目录中初始化指定算法的密钥集合，不启动定期轮换
+func newTestKeys(t *testing.T, alg string) *KeySet {
+	t.Helper()
+	if err := InitKeys(alg, "test-secret", t.TempDir(), 0, time.Hour); err != nil {
+		t.Fatalf("InitKeys(%s): %v", alg, err)
+	}
+	return Keys
+}
+
+func TestKeySetSignAndVerify(t *testing.T) {
+	for _, alg := range []string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			ks := newTestKeys(t, alg)
+			tokenString, err := ks.Sign(jwt.RegisteredClaims{Subject: "42"})
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, ks.Keyfunc)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if token.Method.Alg() != alg {
+				t.Errorf("alg = %s, want %s", token.Method.Alg(), alg)
+			}
+
+			_, hasKid := token.Header["kid"]
+			if alg == AlgHS256 {
+				if hasKid || len(ks.PublicKeys()) != 0 {
+					t.Errorf("HS256 should not publish keys or set kid")
+				}
+				return
+			}
+			keys := ks.PublicKeys()
+			if !hasKid || len(keys) != 1 || token.Header["kid"] != keys[0].Kid {
+				t.Errorf("kid = %v, published keys = %d", token.Header["kid"], len(keys))
+			}
+		})
+	}
+}
+
+func TestKeySetRejectsForeignTokens(t *testing.T) {
+	es := newTestKeys(t, AlgES256)
+	other := newTestKeys(t, AlgES256)
+	hs := newTestKeys(t, AlgHS256)
+
+	foreign, err := other.Sign(jwt.RegisteredClaims{Subject: "42"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	symmetric, err := hs.Sign(jwt.RegisteredClaims{Subject: "42"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		keys  *KeySet
+		token string
+	}{
+		{"unknown kid", es, foreign},
+		{"HS256 token against asymmetric keys", es, symmetric},
+		{"asymmetric token against HS256", hs, foreign},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := jwt.ParseWithClaims(tt.token, &jwt.RegisteredClaims{}, tt.keys.Keyfunc); err == nil {
+				t.Error("token accepted, want error")
+			}
+		})
+	}
+}
+
+func TestKeySetLoadsKeysCreatedByOtherInstances(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitKeys(AlgES256, "", dir, 0, time.Hour); err != nil {
+		t.Fatalf("InitKeys: %v", err)
+	}
+	ks := Keys
+
+	// 另一个实例在共享目录中生成了新密钥并用它签发令牌
+	signWithNewKey := func() string {
+		t.Helper()
+		key, err := generateKey(dir, AlgES256, time.Now())
+		if err != nil {
+			t.Fatalf("generateKey: %v", err)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{Subject: "42"})
+		token.Header["kid"] = key.Kid
+		tokenString, err := token.SignedString(key.Private)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return tokenString
+	}
+
+	if _, err := jwt.ParseWithClaims(signWithNewKey(), &jwt.RegisteredClaims{}, ks.Keyfunc); err != nil {
+		t.Fatalf("token signed by new shared key rejected: %v", err)
+	}
+
+	// 重新加载有最短间隔，间隔内的未知 kid 不再读盘
+	if _, err := jwt.ParseWithClaims(signWithNewKey(), &jwt.RegisteredClaims{}, ks.Keyfunc); err == nil {
+		t.Error("reload within minimum interval, want unknown key error")
+	}
+}
+
+func TestLoadKeysCreatedAtIgnoresModTime(t *testing.T) {
+	dir := t.TempDir()
+	createdAt := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	key, err := generateKey(dir, AlgEdDSA, createdAt)
+	if err != nil {
+		t.Fatalf("generateKey: %v", err)
+	}
+
+	// 复制或备份恢复会改变文件修改时间
+	file := filepath.Join(dir, key.Kid+".pem")
+	if err := os.Chtimes(file, time.Now(), time.Now()); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	keys, err := loadKeys(dir)
+	if err != nil {
+		t.Fatalf("loadKeys: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("loaded %d keys, want 1", len(keys))
+	}
+	if !keys[0].CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v", keys[0].CreatedAt, createdAt)
+	}
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+)
+
+// LockoutPolicy 锁定策略：连续失败 Threshold 次后锁定 Base，此后每次失败锁定时长翻倍，最长 Max
+type LockoutPolicy struct {
+	Threshold int           // 触发锁定的连续失败次数，0 表示不锁定
+	Base      time.Duration // 首次锁定时长
+	Max       time.Duration // 最长锁定时长
+}
+
+// LockDuration 计算第 failures 次连续失败后的锁定时长
+func (p LockoutPolicy) LockDuration(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+	d := p.Base
+	for i := p.Threshold; i < failures && d < p.Max; i++ {
+		d *= 2
+	}
+	if p.Max > 0 && d > p.Max {
+		d = p.Max
+	}
+	return d
+}
+
+// LoginGuardOptions 登录防护配置
+type LoginGuardOptions struct {
+	Account LockoutPolicy // 按账号计数的锁定策略
+	IP      LockoutPolicy // 按来源IP计数的锁定策略
+	Window  time.Duration // 距上次失败超过该时长后重新计数
+	Clock   Clock         // 为 nil 时使用系统时钟
+}
+
+// LoginGuard 按账号和来源IP统计连续登录失败，失败过多时锁定，防止撞库和暴力破解
+type LoginGuard struct {
+	opts LoginGuardOptions
+}
+
+// Guard 全局登录防护
+var Guard *LoginGuard
+
+// InitLoginGuard 初始化全局登录防护
+func InitLoginGuard(opts LoginGuardOptions) {
+	Guard = NewLoginGuard(opts)
+}
+
+// NewLoginGuard 创建登录防护
+func NewLoginGuard(opts LoginGuardOptions) *LoginGuard {
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	return &LoginGuard{opts: opts}
+}
+
+// LoginAccountKey 账号的失败计数键：已注册用户按用户ID计数（用户名和邮箱共享），
+// 不存在的账号按填写内容计数，使其与已注册账号同样会被锁定，避免据此探测账号是否存在
+func LoginAccountKey(user *db.User, identifier string) string {
+	if user != nil {
+		return "id:" + strconv.Itoa(user.ID)
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// Locked 返回账号或IP剩余的锁定时长，未锁定时返回 0
+func (g *LoginGuard) Locked(accountKey, ip string) (time.Duration, error) {
+	now := g.opts.Clock.Now()
+	var remaining time.Duration
+	for _, k := range [][2]string{{db.LockoutScopeAccount, accountKey}, {db.LockoutScopeIP, ip}} {
+		lockout, err := db.GetLoginLockout(k[0], k[1])
+		if err != nil {
+			return 0, err
+		}
+		if lockout != nil && lockout.LockedUntil != nil && lockout.LockedUntil.After(now) {
+			if d := lockout.LockedUntil.Sub(now); d > remaining {
+				remaining = d
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// Fail 记录一次失败，返回因此产生的锁定时长（未达到阈值时为 0）
+func (g *LoginGuard) Fail(accountKey, ip string) (time.Duration, error) {
+	now := g.opts.Clock.Now()
+	account, err := db.RecordLoginFailure(db.LockoutScopeAccount, accountKey, now, g.opts.Window, g.opts.Account.LockDuration)
+	if err != nil {
+		return 0, err
+	}
+	byIP, err := db.RecordLoginFailure(db.LockoutScopeIP, ip, now, g.opts.Window, g.opts.IP.LockDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	var remaining time.Duration
+	for _, lockout := range []*db.LoginLockout{account, byIP} {
+		if lockout.LockedUntil != nil {
+			if d := lockout.LockedUntil.Sub(now); d > remaining {
+				remaining = d
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// Succeed 登录成功后清除账号的失败计数，并将来源IP的失败计数减一（ip 为空时跳过）
+// IP 计数只递减不清零，共用出口IP的正常用户登录后计数逐步回落，
+// 攻击者也无法用自己的账号登录一次就重置撞库计数
+func (g *LoginGuard) Succeed(accountKey, ip string) error {
+	if err := db.ResetLoginFailures(db.LockoutScopeAccount, accountKey); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return db.DecayLoginFailures(db.LockoutScopeIP, ip)
+}
+
+// Cleanup 清理已过期的失败计数
+func (g *LoginGuard) Cleanup() error {
+	_, err := db.DeleteExpiredLoginLockouts(g.opts.Clock.Now().Add(-g.opts.Window))
+	return err
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+)
+
+// RevocationStore 已吊销访问令牌的存储，按令牌ID（jti）记录，令牌过期后可清理
+type RevocationStore interface {
+	// Revoke 吊销令牌，expiresAt 为令牌原过期时间
+	Revoke(jti string, expiresAt time.Time) error
+	// IsRevoked 检查令牌是否已被吊销
+	IsRevoked(jti string) (bool, error)
+	// Cleanup 清理已过期的吊销记录
+	Cleanup() error
+}
+
+// Revocations 当前使用的吊销存储，启动时由 InitRevocationStore 设置
+var Revocations RevocationStore = NewMemoryStore()
+
+// 吊销存储类型
+const (
+	StoreMemory = "memory" // 进程内存储，适用于单实例部署
+	StoreMySQL  = "mysql"  // 数据库存储，适用于多实例部署
+)
+
+// InitRevocationStore 根据类型初始化吊销存储，并按 interval 定期清理过期记录
+func InitRevocationStore(kind string, interval time.Duration) {
+	switch kind {
+	case StoreMySQL:
+		Revocations = MySQLStore{}
+	default:
+		Revocations = NewMemoryStore()
+	}
+	startCleanup(Revocations, interval)
+}
+
+// startCleanup 启动后台清理任务，定期清理过期的吊销记录、刷新令牌、邮件令牌和登录失败计数，
+// 并处理到期的律师认证，interval 不大于 0 时不启动
+func startCleanup(store RevocationStore, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := store.Cleanup(); err != nil {
+				log.Printf("清理已吊销令牌失败: %v", err)
+			}
+			if _, err := db.DeleteExpiredRefreshTokens(); err != nil {
+				log.Printf("清理过期刷新令牌失败: %v", err)
+			}
+			if _, err := db.DeleteExpiredEmailTokens(); err != nil {
+				log.Printf("清理过期邮件令牌失败: %v", err)
+			}
+			if _, err := db.ExpireLawyerVerifications(); err != nil {
+				log.Printf("处理过期律师认证失败: %v", err)
+			}
+			if Guard != nil {
+				if err := Guard.Cleanup(); err != nil {
+					log.Printf("清理登录失败计数失败: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// MemoryStore 进程内的吊销存储
+type MemoryStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore 创建进程内吊销存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{revoked: make(map[string]time.Time), now: time.Now}
+}
+
+// Revoke 吊销令牌
+func (s *MemoryStore) Revoke(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+// IsRevoked 检查令牌是否已被吊销
+func (s *MemoryStore) IsRevoked(jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.revoked[jti]
+	return ok, nil
+}
+
+// Cleanup 清理已过期的吊销记录
+func (s *MemoryStore) Cleanup() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for jti, expiresAt := range s.revoked {
+		if expiresAt.Before(now) {
+			delete(s.revoked, jti)
+		}
+	}
+	return nil
+}
+
+// MySQLStore 基于 revoked_tokens 表的吊销存储，多个实例共享
+type MySQLStore struct{}
+
+// Revoke 吊销令牌
+func (MySQLStore) Revoke(jti string, expiresAt time.Time) error {
+	return db.RevokeToken(jti, expiresAt)
+}
+
+// IsRevoked 检查令牌是否已被吊销
+func (MySQLStore) IsRevoked(jti string) (bool, error) {
+	return db.IsTokenRevoked(jti)
+}
+
+// Cleanup 清理已过期的吊销记录
+func (MySQLStore) Cleanup() error {
+	_, err := db.DeleteExpiredRevokedTokens()
+	return err
+}
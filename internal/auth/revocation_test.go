@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	if err := store.Revoke("expired", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := store.Revoke("active", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	tests := []struct {
+		jti           string
+		before, after bool // 清理前后是否仍视为已吊销
+	}{
+		{"expired", true, false},
+		{"active", true, true},
+		{"unknown", false, false},
+	}
+	check := func(stage string, want func(before, after bool) bool) {
+		for _, tt := range tests {
+			revoked, err := store.IsRevoked(tt.jti)
+			if err != nil {
+				t.Fatalf("IsRevoked(%q): %v", tt.jti, err)
+			}
+			if w := want(tt.before, tt.after); revoked != w {
+				t.Errorf("%s: IsRevoked(%q) = %v, want %v", stage, tt.jti, revoked, w)
+			}
+		}
+	}
+
+	check("before cleanup", func(before, _ bool) bool { return before })
+	if err := store.Cleanup(); err != nil {
+		t.Fatalf("Cleanup: %v", err)
+	}
+	check("after cleanup", func(_, after bool) bool { return after })
+}
@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// 令牌解析错误
+var (
+	ErrTokenExpired = errors.New("认证令牌已过期")
+	ErrTokenInvalid = errors.New("无效的认证令牌")
+)
+
+// clockSkew 允许的实例间时钟偏差，用于 iat/nbf 校验
+const clockSkew = 30 * time.Second
+
+// iat、exp 精确到毫秒，与 users.tokens_valid_after 的精度一致，
+// 退出所有设备后同一秒内重新登录签发的令牌不会被误判为失效
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
+// mfaTokenTTL 两步登录中间令牌的有效期
+const mfaTokenTTL = 5 * time.Minute
+
+// 令牌用途，访问令牌的用途为空
+const (
+	PurposeMFAPending    = "mfa_pending"    // 已通过密码验证、等待两步验证
+	PurposeVerifyEmail   = "verify_email"   // 邮箱验证链接
+	PurposeResetPassword = "reset_password" // 重置密码链接
+)
+
+// Clock 时间来源，测试时可替换为固定时间
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc 将函数适配为 Clock
+type ClockFunc func() time.Time
+
+// Now 返回当前时间
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock 系统时钟
+var SystemClock Clock = ClockFunc(time.Now)
+
+// Claims 访问令牌声明
+type Claims struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Role     int    `json:"role"`
+	Purpose  string `json:"purpose,omitempty"`
+	Email    string `json:"email,omitempty"` // 仅邮件令牌携带，邮箱变更后令牌失效
+	jwt.RegisteredClaims
+}
+
+// IssuedToken 签发的访问令牌
+type IssuedToken struct {
+	Token     string
+	ID        string // jti
+	ExpiresAt time.Time
+}
+
+// RefreshToken 签发的不透明刷新令牌，数据库只保存 Hash
+type RefreshToken struct {
+	Token     string
+	Hash      string
+	ExpiresAt time.Time
+}
+
+// TokenOptions 令牌服务配置
+type TokenOptions struct {
+	Issuer     string        // iss
+	Audience   string        // aud
+	AccessTTL  time.Duration // 访问令牌有效期
+	RefreshTTL time.Duration // 刷新令牌有效期
+	VerifyTTL  time.Duration // 邮箱验证令牌有效期
+	ResetTTL   time.Duration // 重置密码令牌有效期
+	Clock      Clock         // 为 nil 时使用系统时钟
+}
+
+// TokenService 负责令牌的签发与解析，统一声明结构、有效期和签发者/受众校验
+type TokenService struct {
+	keys *KeySet
+	opts TokenOptions
+}
+
+// Tokens 当前使用的令牌服务，启动时由 InitTokens 设置
+var Tokens *TokenService
+
+// NewTokenService 创建令牌服务
+func NewTokenService(keys *KeySet, opts TokenOptions) *TokenService {
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	return &TokenService{keys: keys, opts: opts}
+}
+
+// InitTokens 使用当前密钥集合初始化全局令牌服务
+func InitTokens(opts TokenOptions) {
+	Tokens = NewTokenService(Keys, opts)
+}
+
+// Now 返回令牌服务使用的当前时间
+func (s *TokenService) Now() time.Time {
+	return s.opts.Clock.Now()
+}
+
+// IssueAccessToken 为用户签发访问令牌
+func (s *TokenService) IssueAccessToken(user *db.User) (*IssuedToken, error) {
+	return s.issue(newClaims(user, ""), s.opts.AccessTTL)
+}
+
+// IssueMFAToken 签发两步登录的中间令牌，只能用于提交两步验证码
+func (s *TokenService) IssueMFAToken(user *db.User) (*IssuedToken, error) {
+	return s.issue(newClaims(user, PurposeMFAPending), mfaTokenTTL)
+}
+
+// IssueEmailToken 签发邮件链接中使用的令牌（邮箱验证、重置密码），令牌绑定用户当前邮箱
+// 令牌本身只保证签名和有效期，一次性使用由调用方记录 jti 保证
+func (s *TokenService) IssueEmailToken(user *db.User, purpose string) (*IssuedToken, error) {
+	var ttl time.Duration
+	switch purpose {
+	case PurposeVerifyEmail:
+		ttl = s.opts.VerifyTTL
+	case PurposeResetPassword:
+		ttl = s.opts.ResetTTL
+	default:
+		return nil, fmt.Errorf("不支持的邮件令牌用途: %s", purpose)
+	}
+	claims := newClaims(user, purpose)
+	claims.Email = user.Email
+	return s.issue(claims, ttl)
+}
+
+// ParseAccessToken 解析访问令牌，其他用途的令牌会被拒绝
+func (s *TokenService) ParseAccessToken(tokenString string) (*Claims, error) {
+	return s.parse(tokenString, "")
+}
+
+// ParseMFAToken 解析两步登录的中间令牌
+func (s *TokenService) ParseMFAToken(tokenString string) (*Claims, error) {
+	return s.parse(tokenString, PurposeMFAPending)
+}
+
+// ParseEmailToken 解析指定用途的邮件令牌
+func (s *TokenService) ParseEmailToken(tokenString, purpose string) (*Claims, error) {
+	if purpose != PurposeVerifyEmail && purpose != PurposeResetPassword {
+		return nil, ErrTokenInvalid
+	}
+	return s.parse(tokenString, purpose)
+}
+
+// newClaims 根据用户信息创建指定用途的声明
+func newClaims(user *db.User, purpose string) Claims {
+	return Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		Purpose:  purpose,
+	}
+}
+
+// issue 补全标准声明并签发令牌
+func (s *TokenService) issue(claims Claims, ttl time.Duration) (*IssuedToken, error) {
+	// 令牌ID，用于退出登录时吊销单个令牌
+	jti, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now()
+	expiresAt := now.Add(ttl)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    s.opts.Issuer,
+		Subject:   strconv.Itoa(claims.UserID),
+		Audience:  jwt.ClaimStrings{s.opts.Audience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        jti,
+	}
+
+	tokenString, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedToken{Token: tokenString, ID: jti, ExpiresAt: expiresAt}, nil
+}
+
+// parse 验证签名并按令牌服务的时钟校验有效期、签发者、受众和用途
+func (s *TokenService) parse(tokenString, purpose string) (*Claims, error) {
+	claims := &Claims{}
+	// 时间相关校验由下方使用服务时钟完成
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	if _, err := parser.ParseWithClaims(tokenString, claims, s.keys.Keyfunc); err != nil {
+		return nil, ErrTokenInvalid
+	}
+
+	now := s.Now()
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, ErrTokenExpired
+	}
+	if !claims.VerifyIssuedAt(now.Add(clockSkew), true) || !claims.VerifyNotBefore(now.Add(clockSkew), false) {
+		return nil, ErrTokenInvalid
+	}
+	if !claims.VerifyIssuer(s.opts.Issuer, true) || !claims.VerifyAudience(s.opts.Audience, true) {
+		return nil, ErrTokenInvalid
+	}
+	if claims.ID == "" || claims.Subject != strconv.Itoa(claims.UserID) || claims.Purpose != purpose {
+		return nil, ErrTokenInvalid
+	}
+	return claims, nil
+}
+
+// IssueRefreshToken 生成不透明的随机刷新令牌
+func (s *TokenService) IssueRefreshToken() (*RefreshToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return &RefreshToken{Token: token, Hash: HashToken(token), ExpiresAt: s.Now().Add(s.opts.RefreshTTL)}, nil
+}
+
+// HashToken 计算令牌的 SHA-256 十六进制哈希，数据库只保存哈希
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewTokenID 生成 32 位十六进制的随机ID，用于 jti 和刷新令牌家族
+func NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
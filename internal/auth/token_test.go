@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+)
+
+// fixedClock 可手动拨动的测试时钟
+type fixedClock struct{ now time.Time }
+
+func (c *fixedClock) Now() time.Time { return c.now }
+
+// newTestTokens 创建使用 HS256 共享密钥和固定时钟的令牌服务
+func newTestTokens(clock Clock, issuer, audience string) *TokenService {
+	keys := &KeySet{alg: AlgHS256, secret: []byte("test-secret"), keys: make(map[string]*SigningKey)}
+	return NewTokenService(keys, TokenOptions{
+		Issuer:    issuer,
+		Audience:  audience,
+		AccessTTL: 15 * time.Minute,
+		VerifyTTL: 24 * time.Hour,
+		ResetTTL:  time.Hour,
+		Clock:     clock,
+	})
+}
+
+var testUser = &db.User{ID: 42, Username: "alice", Email: "alice@example.com", Role: 1}
+
+func TestAccessTokenExpiry(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	tokens := newTestTokens(clock, "lawconnect", "lawconnect-web")
+
+	issued, err := tokens.IssueAccessToken(testUser)
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+	if want := clock.now.Add(15 * time.Minute); !issued.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", issued.ExpiresAt, want)
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wantErr error
+	}{
+		{"just issued", 0, nil},
+		{"before expiry", 15*time.Minute - time.Second, nil},
+		{"at expiry", 15 * time.Minute, ErrTokenExpired},
+		{"after expiry", time.Hour, ErrTokenExpired},
+		{"issued in the future beyond skew", -time.Minute, ErrTokenInvalid},
+		{"issued in the future within skew", -10 * time.Second, nil},
+	}
+	start := clock.now
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.now = start.Add(tt.advance)
+			claims, err := tokens.ParseAccessToken(issued.Token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseAccessToken error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (claims.UserID != testUser.ID || claims.ID != issued.ID) {
+				t.Errorf("claims = %+v, want user %d jti %s", claims, testUser.ID, issued.ID)
+			}
+		})
+	}
+}
+
+func TestTokenIssuerAndAudience(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	issued, err := newTestTokens(clock, "lawconnect", "lawconnect-web").IssueAccessToken(testUser)
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+		wantErr  error
+	}{
+		{"matching", "lawconnect", "lawconnect-web", nil},
+		{"wrong issuer", "other", "lawconnect-web", ErrTokenInvalid},
+		{"wrong audience", "lawconnect", "lawconnect-admin", ErrTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestTokens(clock, tt.issuer, tt.audience).ParseAccessToken(issued.Token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseAccessToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenPurpose(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	tokens := newTestTokens(clock, "lawconnect", "lawconnect-web")
+
+	access, err := tokens.IssueAccessToken(testUser)
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+	mfa, err := tokens.IssueMFAToken(testUser)
+	if err != nil {
+		t.Fatalf("IssueMFAToken: %v", err)
+	}
+	verify, err := tokens.IssueEmailToken(testUser, PurposeVerifyEmail)
+	if err != nil {
+		t.Fatalf("IssueEmailToken: %v", err)
+	}
+	reset, err := tokens.IssueEmailToken(testUser, PurposeResetPassword)
+	if err != nil {
+		t.Fatalf("IssueEmailToken: %v", err)
+	}
+	if _, err := tokens.IssueEmailToken(testUser, PurposeMFAPending); err == nil {
+		t.Error("IssueEmailToken accepted a non-email purpose")
+	}
+
+	parseAccess := tokens.ParseAccessToken
+	parseMFA := tokens.ParseMFAToken
+	parseVerify := func(s string) (*Claims, error) { return tokens.ParseEmailToken(s, PurposeVerifyEmail) }
+	parseReset := func(s string) (*Claims, error) { return tokens.ParseEmailToken(s, PurposeResetPassword) }
+
+	tests := []struct {
+		name  string
+		token string
+		parse func(string) (*Claims, error)
+		ok    bool
+	}{
+		{"access as access", access.Token, parseAccess, true},
+		{"mfa as access", mfa.Token, parseAccess, false},
+		{"verify as access", verify.Token, parseAccess, false},
+		{"mfa as mfa", mfa.Token, parseMFA, true},
+		{"access as mfa", access.Token, parseMFA, false},
+		{"verify as verify", verify.Token, parseVerify, true},
+		{"reset as verify", reset.Token, parseVerify, false},
+		{"reset as reset", reset.Token, parseReset, true},
+		{"access as reset", access.Token, parseReset, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.token)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrTokenInvalid) {
+				t.Errorf("error = %v, want %v", err, ErrTokenInvalid)
+			}
+		})
+	}
+
+	claims, err := parseVerify(verify.Token)
+	if err != nil {
+		t.Fatalf("ParseEmailToken: %v", err)
+	}
+	if claims.Email != testUser.Email {
+		t.Errorf("Email = %q, want %q", claims.Email, testUser.Email)
+	}
+}
+
+func TestTokenTamperedSignature(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	issued, err := newTestTokens(clock, "lawconnect", "lawconnect-web").IssueAccessToken(testUser)
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+
+	other := newTestTokens(clock, "lawconnect", "lawconnect-web")
+	other.keys.secret = []byte("another-secret")
+	if _, err := other.ParseAccessToken(issued.Token); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("error = %v, want %v", err, ErrTokenInvalid)
+	}
+}
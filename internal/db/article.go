@@ -1,7 +1,24 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
 	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/utils/text"
+)
+
+// 文章摘要长度（字符数）
+const excerptLength = 120
+
+// summaryContentSQL 列表只读取正文开头的片段和正文总长度，用于生成摘要、封面和估算阅读时长，
+// 片段留有余量以便去除 Markdown/HTML 标记后仍够摘要长度，文章表别名为 a
+const summaryContentSQL = "LEFT(a.content, 1000), CHAR_LENGTH(a.content)"
+
+// 文章可见状态，对应 articles.is_visible
+const (
+	ArticleHidden  = 0 // 不可见（待审核或已下架）
+	ArticleVisible = 1 // 可见
 )
 
 // Article 文章数据模型
@@ -9,45 +26,169 @@ type Article struct {
 	ID           int       `json:"id"`
 	Title        string    `json:"title"`
 	Content      string    `json:"content"`
+	CoverURL     string    `json:"cover_url"`
 	CreatedAt    time.Time `json:"created_at"`
 	Likes        int       `json:"likes"`
 	CommentCount int       `json:"comment_count"`
 	CategoryID   int       `json:"category_id"`
+	RegionCode   string    `json:"region_code"` // 适用地区的行政区划代码，为空表示不限地区
+	UserID       int       `json:"user_id"`
+	IsVisible    int       `json:"is_visible"`
+	// LawyerVerified 作者是否为认证律师
+	LawyerVerified bool `json:"lawyer_verified"`
+	// LinkedContent 法条引用转换为链接后的正文，只在详情中返回
+	LinkedContent string `json:"linked_content,omitempty"`
+}
+
+// ArticleSummary 文章列表使用的轻量投影，不包含完整正文
+type ArticleSummary struct {
+	ID             int       `json:"id"`
+	Title          string    `json:"title"`
+	Excerpt        string    `json:"excerpt"`
+	CoverURL       string    `json:"cover_url"`
+	ReadingMinutes int       `json:"reading_minutes"`
+	CreatedAt      time.Time `json:"created_at"`
+	Likes          int       `json:"likes"`
+	CommentCount   int       `json:"comment_count"`
+	CategoryID     int       `json:"category_id"`
+	RegionCode     string    `json:"region_code"`
+	UserID         int       `json:"user_id"`
+	Author         string    `json:"author"`
+	LawyerVerified bool      `json:"lawyer_verified"` // 作者是否为认证律师
+}
+
+// newArticleSummary 根据正文开头的片段和正文总字符数生成摘要、阅读时长和封面
+func newArticleSummary(summary ArticleSummary, content string, contentLength int) ArticleSummary {
+	summary.Excerpt = text.Excerpt(content, excerptLength)
+	summary.ReadingMinutes = text.ReadingMinutesSample(content, contentLength)
+	if summary.CoverURL == "" {
+		summary.CoverURL = text.FirstImageURL(content)
+	}
+	return summary
 }
 
-// GetArticlesByCategoryAndOrder 根据分类 ID 和排序条件查询文章列表
-func GetArticlesByCategoryAndOrder(categoryID int, orderClause string) ([]Article, error) {
-	query := "SELECT id, title, content, created_at, likes, comment_count, category_id FROM articles WHERE category_id = ? ORDER BY " + orderClause
-	rows, err := DB.Query(query, categoryID)
+// ListArticlesByCategories 分页查询一组分类及其子分类下的可见文章，支持偏移分页和游标分页，
+// regionCode 不为空时只查询该地区及其下级地区的文章
+func ListArticlesByCategories(categoryIDs []int, regionCode string, sort ArticleSort, page Pagination) (*ArticlePage, error) {
+	column, ok := articleSortColumns[sort]
+	if !ok {
+		return nil, errors.New("不支持的排序方式")
+	}
+	if len(categoryIDs) == 0 {
+		return nil, errors.New("未指定分类")
+	}
+	page.Normalize()
+
+	where := " WHERE a.category_id IN " + categorySubtreeSQL(len(categoryIDs)) + " AND a.is_visible = 1"
+	args := make([]interface{}, 0, len(categoryIDs)+4)
+	for _, id := range categoryIDs {
+		args = append(args, id)
+	}
+	if regionCode != "" {
+		where += " AND a.region_code IN " + regionSubtreeSQL
+		args = append(args, regionCode)
+	}
+	return listArticles(where, args, column, sort, page)
+}
+
+// ListArticlesByUser 分页查询用户发布的可见文章
+func ListArticlesByUser(userID int, sort ArticleSort, page Pagination) (*ArticlePage, error) {
+	column, ok := articleSortColumns[sort]
+	if !ok {
+		return nil, errors.New("不支持的排序方式")
+	}
+	page.Normalize()
+	return listArticles(" WHERE a.user_id = ? AND a.is_visible = 1", []interface{}{userID}, column, sort, page)
+}
+
+// listArticles 按筛选条件分页查询文章摘要，where 中的文章表别名为 a
+func listArticles(where string, args []interface{}, column string, sort ArticleSort, page Pagination) (*ArticlePage, error) {
+	// 统计总数
+	result := &ArticlePage{PageSize: page.PageSize, List: []ArticleSummary{}}
+	err := DB.QueryRow("SELECT COUNT(*) FROM articles a"+where, args...).Scan(&result.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT a.id, a.title, " + summaryContentSQL + ", a.cover_url, a.created_at, a.likes, a.comment_count, a.category_id, a.region_code, a.user_id, u.username, " +
+		lawyerBadgeSQL("a.user_id") + " FROM articles a JOIN users u ON u.id = a.user_id" + where
+	if page.Cursor != "" {
+		// 游标分页：从上一页最后一行之后继续
+		value, lastID, err := decodeArticleCursor(sort, page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND (a." + column + " < ? OR (a." + column + " = ? AND a.id < ?))"
+		args = append(args, value, value, lastID)
+	}
+	// 多取一行用于判断是否还有下一页
+	query += " ORDER BY a." + column + " DESC, a.id DESC LIMIT ?"
+	args = append(args, page.PageSize+1)
+	if page.Cursor == "" {
+		query += " OFFSET ?"
+		args = append(args, page.Offset())
+		result.Page = page.Page
+	}
+
+	rows, err := DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var articles []Article
 	for rows.Next() {
-		var art Article
-		if err := rows.Scan(&art.ID, &art.Title, &art.Content, &art.CreatedAt, &art.Likes, &art.CommentCount, &art.CategoryID); err != nil {
+		var summary ArticleSummary
+		var content string
+		var contentLength int
+		if err := rows.Scan(&summary.ID, &summary.Title, &content, &contentLength, &summary.CoverURL, &summary.CreatedAt, &summary.Likes,
+			&summary.CommentCount, &summary.CategoryID, &summary.RegionCode, &summary.UserID, &summary.Author, &summary.LawyerVerified); err != nil {
 			return nil, err
 		}
-		articles = append(articles, art)
+		result.List = append(result.List, newArticleSummary(summary, content, contentLength))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return articles, nil
+	// 生成下一页游标
+	if len(result.List) > page.PageSize {
+		result.List = result.List[:page.PageSize]
+		result.NextCursor = encodeArticleCursor(sort, result.List[page.PageSize-1])
+	}
+	return result, nil
 }
 
-// GetArticleByID 根据文章ID获取单篇文章详情
+// GetArticleByID 根据文章ID获取单篇可见文章详情
 func GetArticleByID(id int) (*Article, error) {
-	query := "SELECT id, title, content, created_at, likes, comment_count, category_id FROM articles WHERE id = ? AND is_visible = 1"
+	return getArticle(id, true)
+}
+
+// GetArticleIncludingHidden 根据文章ID获取文章，包括待审核和已下架的文章，用于作者或管理员编辑、删除
+func GetArticleIncludingHidden(id int) (*Article, error) {
+	return getArticle(id, false)
+}
+
+// getArticle 根据文章ID获取文章，visibleOnly 为 true 时只返回可见文章
+func getArticle(id int, visibleOnly bool) (*Article, error) {
+	query := "SELECT id, title, content, cover_url, created_at, likes, comment_count, category_id, region_code, user_id, is_visible, " +
+		lawyerBadgeSQL("articles.user_id") + " FROM articles WHERE id = ?"
+	if visibleOnly {
+		query += " AND is_visible = 1"
+	}
 	var article Article
 	err := DB.QueryRow(query, id).Scan(
 		&article.ID,
 		&article.Title,
 		&article.Content,
+		&article.CoverURL,
 		&article.CreatedAt,
 		&article.Likes,
 		&article.CommentCount,
 		&article.CategoryID,
+		&article.RegionCode,
+		&article.UserID,
+		&article.IsVisible,
+		&article.LawyerVerified,
 	)
 	if err != nil {
 		return nil, err
@@ -55,21 +196,184 @@ func GetArticleByID(id int) (*Article, error) {
 	return &article, nil
 }
 
+// CreateArticle 创建文章，成功后回填文章ID
+func CreateArticle(article *Article) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = createArticle(tx, article); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// createArticle 在事务中插入文章、回填文章ID并记录正文中的法条引用
+func createArticle(tx *sql.Tx, article *Article) error {
+	result, err := tx.Exec(
+		"INSERT INTO articles (title, content, cover_url, user_id, category_id, region_code, is_visible) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		article.Title, article.Content, article.CoverURL, article.UserID, article.CategoryID, article.RegionCode, article.IsVisible,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 获取自增ID
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	article.ID = int(id)
+	return syncCitations(tx, article.ID, nil, article.Content)
+}
+
+// UpdateArticle 更新文章标题、内容、封面、分类、地区和可见状态，并重新记录正文中的法条引用
+func UpdateArticle(article *Article) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(
+		"UPDATE articles SET title = ?, content = ?, cover_url = ?, category_id = ?, region_code = ?, is_visible = ? WHERE id = ?",
+		article.Title, article.Content, article.CoverURL, article.CategoryID, article.RegionCode, article.IsVisible, article.ID,
+	); err != nil {
+		return err
+	}
+	if err = syncCitations(tx, article.ID, nil, article.Content); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// SetArticleVisibility 设置文章可见状态
+func SetArticleVisibility(id int, visible bool) error {
+	status := ArticleHidden
+	if visible {
+		status = ArticleVisible
+	}
+
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM articles WHERE id = ?)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("文章不存在或已被删除")
+	}
+
+	_, err := DB.Exec("UPDATE articles SET is_visible = ? WHERE id = ?", status, id)
+	return err
+}
+
+// DeleteArticle 删除文章（评论、点赞记录和问题由外键级联删除），deleterID 为执行删除的用户；
+// 未采纳问题的悬赏退还规则见 refundBountyOnDelete
+func DeleteArticle(id, deleterID int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var askerID, bounty int
+	var accepted sql.NullInt64
+	var answered bool
+	err = tx.QueryRow(
+		"SELECT a.user_id, q.bounty, q.accepted_comment_id, "+answerExistsSQL+") "+
+			"FROM questions q JOIN articles a ON a.id = q.article_id WHERE q.article_id = ? FOR UPDATE",
+		id,
+	).Scan(&askerID, &bounty, &accepted, &answered)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = nil
+	case err != nil:
+		return err
+	case refundBountyOnDelete(askerID, deleterID, bounty, accepted.Valid, answered):
+		if err = changePoints(tx, askerID, bounty, PointBountyRefund, id); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM articles WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		err = errors.New("文章不存在或已被删除")
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// refundBountyOnDelete 删除问题时是否向提问者退还悬赏：已采纳的悬赏在采纳时已发放给回答者；
+// 提问者自行删除已有回答的问题时不退，避免收集回答后删除问题取回悬赏；由他人（版主、管理员）删除时总是退还
+func refundBountyOnDelete(askerID, deleterID, bounty int, accepted, answered bool) bool {
+	if bounty <= 0 || accepted {
+		return false
+	}
+	return deleterID != askerID || !answered
+}
+
 // 修改原有的 Comment 结构体，添加 UserID 字段
 type Comment struct {
-	ID        int       `json:"id"`
-	ArticleID int       `json:"article_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	IsVisible int       `json:"is_visible"`
-	Likes     int       `json:"likes"`
-	UserID    int       `json:"user_id"` // 添加用户ID字段
+	ID        int        `json:"id"`
+	ArticleID int        `json:"article_id"`
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"created_at"`
+	IsVisible int        `json:"is_visible"`
+	Likes     int        `json:"likes"`
+	UserID    int        `json:"user_id"` // 添加用户ID字段
+	ParentID  *int       `json:"parent_id"`
+	EditedAt  *time.Time `json:"edited_at"`
+	// LawyerVerified 评论者是否为认证律师
+	LawyerVerified bool `json:"lawyer_verified"`
+	// LinkedContent 法条引用转换为链接后的内容，在文章详情和回复树中返回
+	LinkedContent string `json:"linked_content,omitempty"`
+
+	// 以下字段在组装评论树时填充
+	Replies     []*Comment `json:"replies,omitempty"`
+	ReplyCount  int        `json:"reply_count"`  // 直接回复数
+	ThreadCount int        `json:"thread_count"` // 全部后代回复数
 }
 
 // 需要相应修改 GetCommentsByArticleID 函数
 func GetCommentsByArticleID(articleID int) ([]Comment, error) {
-	query := "SELECT id, article_id, content, created_at, is_visible, likes, user_id FROM comments WHERE article_id = ? AND is_visible = 1 ORDER BY created_at DESC"
-	rows, err := DB.Query(query, articleID)
+	// 已删除的评论只返回占位（不含内容、作者、律师标识和编辑时间），以便保留其下回复的层级
+	query := "SELECT id, article_id, IF(is_visible = ?, '', content), created_at, is_visible, likes, " +
+		"IF(is_visible = ?, 0, user_id), parent_id, IF(is_visible = ?, NULL, edited_at), " +
+		"IF(is_visible = ?, FALSE, " + lawyerBadgeSQL("comments.user_id") + ") FROM comments " +
+		"WHERE article_id = ? AND is_visible IN (?, ?) ORDER BY created_at DESC"
+	rows, err := DB.Query(query, CommentDeleted, CommentDeleted, CommentDeleted, CommentDeleted, articleID, CommentVisible, CommentDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -78,9 +382,18 @@ func GetCommentsByArticleID(articleID int) ([]Comment, error) {
 	var comments []Comment
 	for rows.Next() {
 		var comment Comment
-		if err := rows.Scan(&comment.ID, &comment.ArticleID, &comment.Content, &comment.CreatedAt, &comment.IsVisible, &comment.Likes, &comment.UserID); err != nil {
+		var parentID sql.NullInt64
+		var editedAt sql.NullTime
+		if err := rows.Scan(&comment.ID, &comment.ArticleID, &comment.Content, &comment.CreatedAt, &comment.IsVisible, &comment.Likes, &comment.UserID, &parentID, &editedAt, &comment.LawyerVerified); err != nil {
 			return nil, err
 		}
+		if parentID.Valid {
+			pid := int(parentID.Int64)
+			comment.ParentID = &pid
+		}
+		if editedAt.Valid {
+			comment.EditedAt = &editedAt.Time
+		}
 		comments = append(comments, comment)
 	}
 	return comments, nil
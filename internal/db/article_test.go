@@ -0,0 +1,29 @@
+package db
+
+import "testing"
+
+func TestRefundBountyOnDelete(t *testing.T) {
+	const asker, moderator = 1, 2
+	tests := []struct {
+		name      string
+		deleterID int
+		bounty    int
+		accepted  bool
+		answered  bool
+		want      bool
+	}{
+		{"asker deletes unanswered question", asker, 50, false, false, true},
+		{"asker deletes answered question", asker, 50, false, true, false},
+		{"moderator deletes unanswered question", moderator, 50, false, false, true},
+		{"moderator deletes answered question", moderator, 50, false, true, true},
+		{"accepted question already paid out", moderator, 50, true, true, false},
+		{"no bounty", moderator, 0, false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refundBountyOnDelete(asker, tt.deleterID, tt.bounty, tt.accepted, tt.answered); got != tt.want {
+				t.Errorf("refundBountyOnDelete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
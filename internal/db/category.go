@@ -0,0 +1,227 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// 分类操作错误
+var (
+	ErrCategoryNotFound = errors.New("分类不存在")
+	ErrCategoryCycle    = errors.New("不能将分类移动到自身或其子分类下")
+	ErrCategoryNotEmpty = errors.New("分类或其子分类下仍有文章，无法删除")
+)
+
+// categorySubtreeSQL 生成子查询：返回 n 个指定分类及其所有子孙分类的ID，需要绑定 n 个分类ID参数
+func categorySubtreeSQL(n int) string {
+	return "(WITH RECURSIVE subtree AS (" +
+		"SELECT id FROM categories WHERE id IN (" + placeholders(n) + ") " +
+		"UNION ALL SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id" +
+		") SELECT id FROM subtree)"
+}
+
+// placeholders 生成 n 个以逗号分隔的 SQL 占位符
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// Category 分类数据模型
+type Category struct {
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	ParentID *int        `json:"parent_id"`
+	Children []*Category `json:"children,omitempty"`
+}
+
+// CategoryExists 检查分类是否存在
+func CategoryExists(categoryID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = ?)", categoryID).Scan(&exists)
+	return exists, err
+}
+
+// GetCategoryByID 根据ID获取分类
+func GetCategoryByID(id int) (*Category, error) {
+	category := &Category{}
+	var parentID sql.NullInt64
+	err := DB.QueryRow("SELECT id, name, parent_id FROM categories WHERE id = ?", id).Scan(&category.ID, &category.Name, &parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		pid := int(parentID.Int64)
+		category.ParentID = &pid
+	}
+	return category, nil
+}
+
+// GetCategoryTree 查询全部分类并组装成树，返回所有根分类
+func GetCategoryTree() ([]*Category, error) {
+	rows, err := DB.Query("SELECT id, name, parent_id FROM categories ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var all []*Category
+	byID := make(map[int]*Category)
+	for rows.Next() {
+		category := &Category{}
+		var parentID sql.NullInt64
+		if err := rows.Scan(&category.ID, &category.Name, &parentID); err != nil {
+			return nil, err
+		}
+		if parentID.Valid {
+			pid := int(parentID.Int64)
+			category.ParentID = &pid
+		}
+		all = append(all, category)
+		byID[category.ID] = category
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 按父分类挂载子节点
+	roots := []*Category{}
+	for _, category := range all {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+	return roots, nil
+}
+
+// CreateCategory 创建分类，parentID 为 nil 时创建根分类
+func CreateCategory(category *Category) error {
+	if category.ParentID != nil {
+		exists, err := CategoryExists(*category.ParentID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCategoryNotFound
+		}
+	}
+
+	result, err := DB.Exec("INSERT INTO categories (name, parent_id) VALUES (?, ?)", category.Name, category.ParentID)
+	if err != nil {
+		return err
+	}
+
+	// 获取自增ID
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	category.ID = int(id)
+	return nil
+}
+
+// RenameCategory 修改分类名称
+func RenameCategory(id int, name string) error {
+	if _, err := GetCategoryByID(id); err != nil {
+		return err
+	}
+
+	_, err := DB.Exec("UPDATE categories SET name = ? WHERE id = ?", name, id)
+	return err
+}
+
+// MoveCategory 将分类移动到新的父分类下，parentID 为 nil 时移动为根分类
+func MoveCategory(id int, parentID *int) error {
+	if _, err := GetCategoryByID(id); err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		if _, err := GetCategoryByID(*parentID); err != nil {
+			return err
+		}
+
+		// 新的父分类不能是自身或自身的子孙分类，否则会形成环
+		var cyclic bool
+		err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = ? AND id IN "+categorySubtreeSQL(1)+")", *parentID, id).Scan(&cyclic)
+		if err != nil {
+			return err
+		}
+		if cyclic {
+			return ErrCategoryCycle
+		}
+	}
+
+	_, err := DB.Exec("UPDATE categories SET parent_id = ? WHERE id = ?", parentID, id)
+	return err
+}
+
+// GetCategoryPublishPermission 查询在分类下发布文章所需的权限，取自身或最近的祖先分类上的设置，均未设置时返回空字符串
+func GetCategoryPublishPermission(id int) (string, error) {
+	var permission sql.NullString
+	err := DB.QueryRow(
+		"WITH RECURSIVE ancestors AS ("+
+			"SELECT id, parent_id, publish_permission, 0 AS depth FROM categories WHERE id = ? "+
+			"UNION ALL SELECT c.id, c.parent_id, c.publish_permission, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id"+
+			") SELECT publish_permission FROM ancestors WHERE publish_permission IS NOT NULL ORDER BY depth LIMIT 1",
+		id,
+	).Scan(&permission)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return permission.String, nil
+}
+
+// SetCategoryPublishPermission 设置在分类及其子分类下发布文章所需的权限，permission 为空时继承父分类
+func SetCategoryPublishPermission(id int, permission string) error {
+	if _, err := GetCategoryByID(id); err != nil {
+		return err
+	}
+	if permission != "" {
+		var exists bool
+		if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM permissions WHERE code = ?)", permission).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUnknownPermission
+		}
+	}
+
+	_, err := DB.Exec("UPDATE categories SET publish_permission = NULLIF(?, '') WHERE id = ?", permission, id)
+	return err
+}
+
+// DeleteCategory 删除分类及其子分类（由外键级联删除），分类子树下仍有文章时拒绝删除
+func DeleteCategory(id int) error {
+	var hasArticles bool
+	err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM articles WHERE category_id IN "+categorySubtreeSQL(1)+")", id).Scan(&hasArticles)
+	if err != nil {
+		return err
+	}
+	if hasArticles {
+		return ErrCategoryNotEmpty
+	}
+
+	result, err := DB.Exec("DELETE FROM categories WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/utils/statute"
+)
+
+// CitingComment 引用了法规条文的评论，附带文章标题和作者用户名
+type CitingComment struct {
+	Comment
+	ArticleTitle string `json:"article_title"`
+	Username     string `json:"username"`
+}
+
+// StatuteIndex 法条链接使用的索引：法规名称到法规ID的映射，以及各法规现行版本中存在的条文
+type StatuteIndex struct {
+	date     string                  // 建立索引的日期，跨天后现行版本可能变化
+	titles   map[string]int          // 全称和简称均去掉“中华人民共和国”前缀（见 statute.NormalizeTitle）
+	articles map[int]map[[2]int]bool // 法规ID -> 现行版本中的条号和“之几”
+}
+
+// Lookup 返回引用的法规ID，法规未收录或现行版本中没有该条文时返回 false
+func (ix *StatuteIndex) Lookup(c statute.Citation) (int, bool) {
+	id, ok := ix.titles[statute.NormalizeTitle(c.Title)]
+	if !ok || !ix.articles[id][[2]int{c.Number, c.SubNumber}] {
+		return 0, false
+	}
+	return id, true
+}
+
+// statuteIndexCache 进程内缓存的法条链接索引，导入或删除法规、版本后失效
+var statuteIndexCache struct {
+	mu    sync.Mutex
+	index *StatuteIndex
+}
+
+// GetStatuteIndex 获取法条链接索引，优先使用缓存
+func GetStatuteIndex() (*StatuteIndex, error) {
+	today := time.Now().Format("2006-01-02")
+
+	statuteIndexCache.mu.Lock()
+	defer statuteIndexCache.mu.Unlock()
+	if ix := statuteIndexCache.index; ix != nil && ix.date == today {
+		return ix, nil
+	}
+
+	titles, err := statuteTitleIndex(DB)
+	if err != nil {
+		return nil, err
+	}
+	articles, err := effectiveStatuteArticles(today)
+	if err != nil {
+		return nil, err
+	}
+	statuteIndexCache.index = &StatuteIndex{date: today, titles: titles, articles: articles}
+	return statuteIndexCache.index, nil
+}
+
+// invalidateStatuteIndex 使缓存的法条链接索引失效，在法规或版本变更提交后调用
+func invalidateStatuteIndex() {
+	statuteIndexCache.mu.Lock()
+	statuteIndexCache.index = nil
+	statuteIndexCache.mu.Unlock()
+}
+
+// effectiveStatuteArticles 查询各法规在 date 时现行版本（施行日期不晚于 date 的最新版本）中的条文
+func effectiveStatuteArticles(date string) (map[int]map[[2]int]bool, error) {
+	rows, err := DB.Query(
+		"SELECT v.statute_id, sa.number, sa.sub_number FROM statute_articles sa JOIN statute_versions v ON v.id = sa.version_id "+
+			"WHERE v.effective_date = (SELECT MAX(cur.effective_date) FROM statute_versions cur WHERE cur.statute_id = v.statute_id AND cur.effective_date <= ?)",
+		date,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := make(map[int]map[[2]int]bool)
+	for rows.Next() {
+		var statuteID, number, subNumber int
+		if err := rows.Scan(&statuteID, &number, &subNumber); err != nil {
+			return nil, err
+		}
+		if articles[statuteID] == nil {
+			articles[statuteID] = make(map[[2]int]bool)
+		}
+		articles[statuteID][[2]int{number, subNumber}] = true
+	}
+	return articles, rows.Err()
+}
+
+// statuteTitleIndex 查询法规名称到法规ID的映射，可在事务中调用
+func statuteTitleIndex(q queryer) (map[string]int, error) {
+	rows, err := q.Query("SELECT id, title, short_title FROM statutes")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var title, shortTitle string
+		if err := rows.Scan(&id, &title, &shortTitle); err != nil {
+			return nil, err
+		}
+		index[statute.NormalizeTitle(title)] = id
+		if shortTitle != "" {
+			index[statute.NormalizeTitle(shortTitle)] = id
+		}
+	}
+	return index, rows.Err()
+}
+
+// syncCitations 重新识别文章正文（commentID 为 nil）或评论中的法条引用，只记录已收录的法规，可在事务中调用
+func syncCitations(tx *sql.Tx, articleID int, commentID *int, content string) error {
+	var err error
+	if commentID == nil {
+		_, err = tx.Exec("DELETE FROM statute_citations WHERE article_id = ? AND comment_id IS NULL", articleID)
+	} else {
+		_, err = tx.Exec("DELETE FROM statute_citations WHERE comment_id = ?", *commentID)
+	}
+	if err != nil {
+		return err
+	}
+
+	citations := statute.FindCitations(content)
+	if len(citations) == 0 {
+		return nil
+	}
+	index, err := statuteTitleIndex(tx)
+	if err != nil {
+		return err
+	}
+
+	// 同一条文在正文中多次引用只记录一次
+	seen := make(map[[3]int]bool)
+	var args []interface{}
+	for _, c := range citations {
+		statuteID, ok := index[statute.NormalizeTitle(c.Title)]
+		key := [3]int{statuteID, c.Number, c.SubNumber}
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		args = append(args, statuteID, c.Number, c.SubNumber, articleID, commentID)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO statute_citations (statute_id, number, sub_number, article_id, comment_id) VALUES "
+	for i := 0; i < len(seen); i++ {
+		if i > 0 {
+			query += ","
+		}
+		query += "(" + placeholders(5) + ")"
+	}
+	_, err = tx.Exec(query, args...)
+	return err
+}
+
+// backfillCitations 新收录法规后，重新识别正文中含有该法规名称的文章和评论的引用
+func backfillCitations(tx *sql.Tx, titles ...string) error {
+	var conditions []string
+	var args []interface{}
+	for _, title := range titles {
+		if title = statute.NormalizeTitle(title); title != "" {
+			// 书名号中可能带有“中华人民共和国”前缀，只匹配名称和右书名号
+			conditions = append(conditions, "content LIKE ?")
+			args = append(args, "%"+escapeLike(title)+"》%")
+		}
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	where := " WHERE " + strings.Join(conditions, " OR ")
+
+	type source struct {
+		articleID int
+		commentID *int
+		content   string
+	}
+	var sources []source
+
+	// 先读取全部内容再更新，同一连接上不能在读取结果集时执行其他语句
+	rows, err := tx.Query("SELECT id, content FROM articles"+where, args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var s source
+		if err := rows.Scan(&s.articleID, &s.content); err != nil {
+			rows.Close()
+			return err
+		}
+		sources = append(sources, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.Query("SELECT id, article_id, content FROM comments"+where, args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var s source
+		var commentID int
+		if err := rows.Scan(&commentID, &s.articleID, &s.content); err != nil {
+			rows.Close()
+			return err
+		}
+		s.commentID = &commentID
+		sources = append(sources, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range sources {
+		if err := syncCitations(tx, s.articleID, s.commentID, s.content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListCitingArticles 分页查询正文引用了指定条文的可见文章
+func ListCitingArticles(statuteID, number, subNumber int, sort ArticleSort, page Pagination) (*ArticlePage, error) {
+	column, ok := articleSortColumns[sort]
+	if !ok {
+		return nil, errors.New("不支持的排序方式")
+	}
+	page.Normalize()
+	return listArticles(
+		" WHERE a.id IN (SELECT article_id FROM statute_citations WHERE statute_id = ? AND number = ? AND sub_number = ? AND comment_id IS NULL)"+
+			" AND a.is_visible = 1",
+		[]interface{}{statuteID, number, subNumber}, column, sort, page,
+	)
+}
+
+// ListCitingComments 分页查询引用了指定条文的已通过审核评论（所属文章可见），按发布时间倒序
+func ListCitingComments(statuteID, number, subNumber int, page Pagination) ([]CitingComment, int, error) {
+	page.Normalize()
+
+	from := " FROM statute_citations sc JOIN comments c ON c.id = sc.comment_id JOIN articles a ON a.id = c.article_id " +
+		"JOIN users u ON u.id = c.user_id " +
+		"WHERE sc.statute_id = ? AND sc.number = ? AND sc.sub_number = ? AND c.is_visible = ? AND a.is_visible = 1"
+	args := []interface{}{statuteID, number, subNumber, CommentVisible}
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT c.id, c.article_id, c.content, c.created_at, c.is_visible, c.likes, c.user_id, c.parent_id, c.edited_at, "+
+			lawyerBadgeSQL("c.user_id")+", a.title, u.username"+from+" ORDER BY c.created_at DESC, c.id DESC LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []CitingComment{}
+	for rows.Next() {
+		var c CitingComment
+		var parentID sql.NullInt64
+		var editedAt sql.NullTime
+		if err := rows.Scan(&c.ID, &c.ArticleID, &c.Content, &c.CreatedAt, &c.IsVisible, &c.Likes, &c.UserID, &parentID, &editedAt,
+			&c.LawyerVerified, &c.ArticleTitle, &c.Username); err != nil {
+			return nil, 0, err
+		}
+		if parentID.Valid {
+			pid := int(parentID.Int64)
+			c.ParentID = &pid
+		}
+		if editedAt.Valid {
+			c.EditedAt = &editedAt.Time
+		}
+		list = append(list, c)
+	}
+	return list, total, rows.Err()
+}
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+)
+
+// 评论审核状态，对应 comments.is_visible
+const (
+	CommentPending  = 0 // 审核中
+	CommentVisible  = 1 // 可见
+	CommentRejected = 2 // 审核未通过
+	CommentDeleted  = 3 // 已删除（软删除）
+)
+
+// 评论操作错误
+var (
+	ErrCommentNotFound  = errors.New("评论不存在或已被删除")
+	ErrCommentForbidden = errors.New("只能操作自己的评论")
+)
+
+// 评论树深度限制
+const (
+	DefaultCommentDepth = 3  // 默认返回的评论层数
+	MaxCommentDepth     = 10 // 允许请求的最大评论层数
+)
+
+// GetCommentTree 获取文章的评论树，maxDepth 为返回的层数（顶层评论为第 1 层）
+// 超出层数的回复不返回，但仍计入上层评论的 reply_count 和 thread_count
+func GetCommentTree(articleID int, maxDepth int) ([]*Comment, error) {
+	comments, err := GetCommentsByArticleID(articleID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentTree(comments, nil, maxDepth), nil
+}
+
+// GetCommentReplies 获取某条评论下的回复树，maxDepth 为返回的层数（直接回复为第 1 层）
+func GetCommentReplies(commentID int, maxDepth int) ([]*Comment, error) {
+	var articleID int
+	err := DB.QueryRow("SELECT article_id FROM comments WHERE id = ? AND is_visible = 1", commentID).Scan(&articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := GetCommentsByArticleID(articleID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentTree(comments, &commentID, maxDepth), nil
+}
+
+// buildCommentTree 将平铺的评论组装为以 rootID 为父节点的评论树（rootID 为 nil 表示顶层）
+// 父评论不可见的回复不会出现在树中，已删除且没有可见回复的评论会被移除
+func buildCommentTree(comments []Comment, rootID *int, maxDepth int) []*Comment {
+	if maxDepth < 1 {
+		maxDepth = DefaultCommentDepth
+	}
+	if maxDepth > MaxCommentDepth {
+		maxDepth = MaxCommentDepth
+	}
+
+	// 按父评论分组
+	children := make(map[int][]*Comment)
+	var roots []*Comment
+	for i := range comments {
+		comment := &comments[i]
+		switch {
+		case rootID == nil && comment.ParentID == nil:
+			roots = append(roots, comment)
+		case comment.ParentID != nil:
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		}
+	}
+
+	// 回复按时间正序排列，便于阅读对话
+	for _, replies := range children {
+		sort.SliceStable(replies, func(i, j int) bool {
+			return replies[i].CreatedAt.Before(replies[j].CreatedAt)
+		})
+	}
+	if rootID != nil {
+		roots = children[*rootID]
+	}
+
+	tree := []*Comment{}
+	for _, root := range roots {
+		if attachReplies(root, children, 1, maxDepth) == 0 && root.IsVisible == CommentDeleted {
+			continue
+		}
+		tree = append(tree, root)
+	}
+	return tree
+}
+
+// attachReplies 递归挂载回复并统计回复数（不含已删除的评论），返回该评论的可见后代数量
+func attachReplies(comment *Comment, children map[int][]*Comment, depth, maxDepth int) int {
+	var kept []*Comment
+	for _, reply := range children[comment.ID] {
+		descendants := attachReplies(reply, children, depth+1, maxDepth)
+		if reply.IsVisible == CommentDeleted {
+			if descendants == 0 {
+				continue
+			}
+		} else {
+			comment.ReplyCount++
+			comment.ThreadCount++
+		}
+		comment.ThreadCount += descendants
+		kept = append(kept, reply)
+	}
+	if depth < maxDepth {
+		comment.Replies = kept
+	}
+	return comment.ThreadCount
+}
+
+// GetCommentByID 根据ID获取未删除的评论
+func GetCommentByID(id int) (*Comment, error) {
+	comment := &Comment{}
+	var parentID sql.NullInt64
+	var editedAt sql.NullTime
+	err := DB.QueryRow(
+		"SELECT id, article_id, content, created_at, is_visible, likes, user_id, parent_id, edited_at, "+lawyerBadgeSQL("comments.user_id")+
+			" FROM comments WHERE id = ? AND is_visible <> ?",
+		id, CommentDeleted,
+	).Scan(&comment.ID, &comment.ArticleID, &comment.Content, &comment.CreatedAt, &comment.IsVisible, &comment.Likes, &comment.UserID, &parentID, &editedAt,
+		&comment.LawyerVerified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		pid := int(parentID.Int64)
+		comment.ParentID = &pid
+	}
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+	return comment, nil
+}
+
+// UpdateComment 编辑评论内容并保存编辑前的内容到历史记录，status 为编辑后的审核状态
+// 只有评论作者可以编辑，状态变化时同步调整文章评论计数
+func UpdateComment(commentID, userID int, content string, status int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定评论并校验作者
+	var articleID, ownerID, oldStatus int
+	var oldContent string
+	err = tx.QueryRow("SELECT article_id, user_id, is_visible, content FROM comments WHERE id = ? FOR UPDATE", commentID).
+		Scan(&articleID, &ownerID, &oldStatus, &oldContent)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && oldStatus == CommentDeleted) {
+		err = ErrCommentNotFound
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if ownerID != userID {
+		err = ErrCommentForbidden
+		return err
+	}
+
+	// 保存编辑历史
+	if _, err = tx.Exec("INSERT INTO comment_edits (comment_id, content) VALUES (?, ?)", commentID, oldContent); err != nil {
+		return err
+	}
+
+	// 更新内容和状态
+	_, err = tx.Exec("UPDATE comments SET content = ?, is_visible = ?, edited_at = NOW() WHERE id = ?", content, status, commentID)
+	if err != nil {
+		return err
+	}
+
+	// 文章评论计数只统计已通过审核的评论
+	if err = adjustCommentCount(tx, articleID, oldStatus, status); err != nil {
+		return err
+	}
+
+	// 重新记录评论中的法条引用
+	if err = syncCitations(tx, articleID, &commentID, content); err != nil {
+		return err
+	}
+
+	// 提交事务
+	return tx.Commit()
+}
+
+// DeleteComment 软删除评论，同时清除评论的点赞记录并调整文章评论计数
+// 评论作者或拥有评论管理权限的用户（asModerator 为 true）可以删除，其下的回复保留
+func DeleteComment(commentID, userID int, asModerator bool) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定评论并校验权限
+	var articleID, ownerID, oldStatus int
+	err = tx.QueryRow("SELECT article_id, user_id, is_visible FROM comments WHERE id = ? FOR UPDATE", commentID).
+		Scan(&articleID, &ownerID, &oldStatus)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && oldStatus == CommentDeleted) {
+		err = ErrCommentNotFound
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if ownerID != userID && !asModerator {
+		err = ErrCommentForbidden
+		return err
+	}
+
+	// 标记删除
+	_, err = tx.Exec("UPDATE comments SET is_visible = ?, likes = 0, deleted_at = NOW() WHERE id = ?", CommentDeleted, commentID)
+	if err != nil {
+		return err
+	}
+
+	// 清除点赞记录
+	if _, err = tx.Exec("DELETE FROM comment_likes WHERE comment_id = ?", commentID); err != nil {
+		return err
+	}
+
+	// 调整文章评论计数
+	if err = adjustCommentCount(tx, articleID, oldStatus, CommentDeleted); err != nil {
+		return err
+	}
+
+	// 提交事务
+	return tx.Commit()
+}
+
+// CommentEdit 评论编辑历史记录
+type CommentEdit struct {
+	ID       int       `json:"id"`
+	Content  string    `json:"content"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// GetCommentEdits 获取评论的编辑历史，按编辑时间倒序
+func GetCommentEdits(commentID int) ([]CommentEdit, error) {
+	rows, err := DB.Query("SELECT id, content, edited_at FROM comment_edits WHERE comment_id = ? ORDER BY id DESC", commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edits := []CommentEdit{}
+	for rows.Next() {
+		var edit CommentEdit
+		if err := rows.Scan(&edit.ID, &edit.Content, &edit.EditedAt); err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	return edits, rows.Err()
+}
+
+// adjustCommentCount 评论状态从 oldStatus 变为 newStatus 时调整文章评论计数
+func adjustCommentCount(ex execer, articleID, oldStatus, newStatus int) error {
+	var err error
+	switch {
+	case oldStatus != CommentVisible && newStatus == CommentVisible:
+		_, err = ex.Exec("UPDATE articles SET comment_count = comment_count + 1 WHERE id = ?", articleID)
+	case oldStatus == CommentVisible && newStatus != CommentVisible:
+		_, err = ex.Exec("UPDATE articles SET comment_count = comment_count - 1 WHERE id = ? AND comment_count > 0", articleID)
+	}
+	return err
+}
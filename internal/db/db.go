@@ -35,6 +35,11 @@ func InitDB(dsn string) {
 	if err != nil {
 		log.Fatal("执行数据库初始化 SQL 失败:", err)
 	}
+
+	// 为已存在的旧表补齐新增的列和索引
+	if err := migrate(); err != nil {
+		log.Fatal("数据库迁移失败:", err)
+	}
 }
 
 // execSchemaSQL 执行外部 SQL 文件
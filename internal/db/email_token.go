@@ -0,0 +1,63 @@
+package db
+
+import (
+	"time"
+)
+
+// CreateEmailToken 记录新签发的邮件令牌，同一用途此前未使用的令牌一并作废（只有最新的链接有效）
+func CreateEmailToken(jti string, userID int, purpose string, expiresAt time.Time) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec(
+		"UPDATE email_tokens SET used_at = NOW() WHERE user_id = ? AND purpose = ? AND used_at IS NULL",
+		userID, purpose,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO email_tokens (jti, user_id, purpose, expires_at) VALUES (?, ?, ?, ?)",
+		jti, userID, purpose, expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// UseEmailToken 使用邮件令牌，令牌不存在、已使用或已过期时返回 false
+func UseEmailToken(jti string, userID int, purpose string) (bool, error) {
+	result, err := DB.Exec(
+		"UPDATE email_tokens SET used_at = NOW() WHERE jti = ? AND user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > NOW()",
+		jti, userID, purpose,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// DeleteExpiredEmailTokens 清理已过期的邮件令牌
+func DeleteExpiredEmailTokens() (int64, error) {
+	result, err := DB.Exec("DELETE FROM email_tokens WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
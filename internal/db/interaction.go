@@ -1,11 +1,34 @@
 package db
 
 import (
+	"database/sql"
 	"errors"
 )
 
-// AddComment 添加评论到文章
-func AddComment(articleID int, content string, userID int) (int, error) {
+// AddComment 添加评论到文章，status 为评论的初始审核状态
+func AddComment(articleID int, content string, userID int, status int) (int, error) {
+	return insertComment(articleID, nil, content, userID, status)
+}
+
+// AddReply 回复评论，返回新评论ID和所属文章ID，status 为评论的初始审核状态
+func AddReply(parentID int, content string, userID int, status int) (int, int, error) {
+	// 父评论必须存在且已通过审核
+	var articleID int
+	err := DB.QueryRow("SELECT article_id FROM comments WHERE id = ? AND is_visible = 1", parentID).Scan(&articleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, errors.New("评论不存在或未通过审核")
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	commentID, err := insertComment(articleID, &parentID, content, userID, status)
+	return commentID, articleID, err
+}
+
+// insertComment 插入评论并更新文章评论计数，parentID 为 nil 表示顶层评论
+// 文章评论计数只统计已通过审核的评论
+func insertComment(articleID int, parentID *int, content string, userID int, status int) (int, error) {
 	// 开启事务
 	tx, err := DB.Begin()
 	if err != nil {
@@ -19,8 +42,8 @@ func AddComment(articleID int, content string, userID int) (int, error) {
 
 	// 1. 插入评论记录
 	res, err := tx.Exec(
-		"INSERT INTO comments (article_id, content, is_visible, user_id) VALUES (?, ?, ?, ?)",
-		articleID, content, 1, userID,
+		"INSERT INTO comments (article_id, parent_id, content, is_visible, user_id) VALUES (?, ?, ?, ?, ?)",
+		articleID, parentID, content, status, userID,
 	)
 	if err != nil {
 		return 0, err
@@ -32,12 +55,20 @@ func AddComment(articleID int, content string, userID int) (int, error) {
 		return 0, err
 	}
 
-	// 2. 更新文章的评论计数
-	_, err = tx.Exec(
-		"UPDATE articles SET comment_count = comment_count + 1 WHERE id = ?",
-		articleID,
-	)
-	if err != nil {
+	// 2. 更新文章的评论计数（回复同样计入，待审核评论在通过后计入）
+	if status == CommentVisible {
+		_, err = tx.Exec(
+			"UPDATE articles SET comment_count = comment_count + 1 WHERE id = ?",
+			articleID,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// 3. 记录评论中的法条引用
+	id := int(commentID)
+	if err = syncCitations(tx, articleID, &id, content); err != nil {
 		return 0, err
 	}
 
@@ -46,7 +77,7 @@ func AddComment(articleID int, content string, userID int) (int, error) {
 		return 0, err
 	}
 
-	return int(commentID), nil
+	return id, nil
 }
 
 // LikeArticle 为文章点赞，防止重复点赞
@@ -0,0 +1,379 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// 律师认证状态，对应 lawyer_verifications.status
+const (
+	LawyerPending  = "pending"  // 待审核
+	LawyerApproved = "approved" // 已通过
+	LawyerRejected = "rejected" // 已驳回
+	LawyerExpired  = "expired"  // 已过期
+)
+
+// 律师认证错误
+var (
+	ErrVerificationNotFound = errors.New("认证申请不存在")
+	ErrVerificationPending  = errors.New("已有待审核的认证申请，请等待审核结果")
+	ErrVerificationReviewed = errors.New("该申请已审核")
+	ErrVerificationSelf     = errors.New("不能审核自己的认证申请")
+)
+
+// LawyerDocument 律师认证的证明材料
+type LawyerDocument struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// LawyerVerification 律师认证申请
+type LawyerVerification struct {
+	ID            int              `json:"id"`
+	UserID        int              `json:"user_id"`
+	Username      string           `json:"username,omitempty"`
+	LicenseNumber string           `json:"license_number"`
+	LawFirm       string           `json:"law_firm"`
+	Jurisdiction  string           `json:"jurisdiction"`
+	Status        string           `json:"status"`
+	ReviewNote    string           `json:"review_note"`
+	ReviewedBy    *int             `json:"reviewed_by"`
+	ReviewedAt    *time.Time       `json:"reviewed_at"`
+	ExpiresAt     *time.Time       `json:"expires_at"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Documents     []LawyerDocument `json:"documents"`
+}
+
+// ValidLawyerStatus 检查认证状态是否有效
+func ValidLawyerStatus(status string) bool {
+	switch status {
+	case LawyerPending, LawyerApproved, LawyerRejected, LawyerExpired:
+		return true
+	}
+	return false
+}
+
+// lawyerBadgeSQL 生成条件表达式：userColumn 对应的用户当前是否为认证律师
+func lawyerBadgeSQL(userColumn string) string {
+	return "EXISTS(SELECT 1 FROM lawyer_verifications lv WHERE lv.user_id = " + userColumn +
+		" AND lv.status = '" + LawyerApproved + "' AND (lv.expires_at IS NULL OR lv.expires_at > NOW()))"
+}
+
+// IsVerifiedLawyer 检查用户当前是否为认证律师
+func IsVerifiedLawyer(userID int) (bool, error) {
+	var verified bool
+	err := DB.QueryRow("SELECT "+lawyerBadgeSQL("?"), userID).Scan(&verified)
+	return verified, err
+}
+
+// lawyerVerificationColumns 认证申请查询的列，与 scanLawyerVerification 的顺序一致
+const lawyerVerificationColumns = "v.id, v.user_id, u.username, v.license_number, v.law_firm, v.jurisdiction, v.status, " +
+	"v.review_note, v.reviewed_by, v.reviewed_at, v.expires_at, v.created_at"
+
+// scanLawyerVerification 从查询结果中读取认证申请
+func scanLawyerVerification(row rowScanner) (*LawyerVerification, error) {
+	v := &LawyerVerification{Documents: []LawyerDocument{}}
+	var reviewedBy sql.NullInt64
+	var reviewedAt, expiresAt sql.NullTime
+	if err := row.Scan(&v.ID, &v.UserID, &v.Username, &v.LicenseNumber, &v.LawFirm, &v.Jurisdiction, &v.Status,
+		&v.ReviewNote, &reviewedBy, &reviewedAt, &expiresAt, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	if reviewedBy.Valid {
+		id := int(reviewedBy.Int64)
+		v.ReviewedBy = &id
+	}
+	if reviewedAt.Valid {
+		v.ReviewedAt = &reviewedAt.Time
+	}
+	if expiresAt.Valid {
+		v.ExpiresAt = &expiresAt.Time
+	}
+	return v, nil
+}
+
+// getLawyerVerification 按条件查询一条认证申请及其证明材料
+func getLawyerVerification(where string, args ...interface{}) (*LawyerVerification, error) {
+	row := DB.QueryRow("SELECT "+lawyerVerificationColumns+" FROM lawyer_verifications v JOIN users u ON u.id = v.user_id"+where, args...)
+	v, err := scanLawyerVerification(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVerificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := DB.Query("SELECT name, url FROM lawyer_verification_documents WHERE verification_id = ? ORDER BY id", v.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc LawyerDocument
+		if err := rows.Scan(&doc.Name, &doc.URL); err != nil {
+			return nil, err
+		}
+		v.Documents = append(v.Documents, doc)
+	}
+	return v, rows.Err()
+}
+
+// GetLawyerVerification 根据ID获取认证申请
+func GetLawyerVerification(id int) (*LawyerVerification, error) {
+	return getLawyerVerification(" WHERE v.id = ?", id)
+}
+
+// GetLatestLawyerVerification 获取用户最近一次提交的认证申请
+func GetLatestLawyerVerification(userID int) (*LawyerVerification, error) {
+	return getLawyerVerification(" WHERE v.user_id = ? ORDER BY v.id DESC LIMIT 1", userID)
+}
+
+// CreateLawyerVerification 提交律师认证申请，同一用户同时只能有一条待审核的申请
+func CreateLawyerVerification(v *LawyerVerification) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定用户行，避免并发提交多条待审核申请
+	var userID int
+	if err = tx.QueryRow("SELECT id FROM users WHERE id = ? FOR UPDATE", v.UserID).Scan(&userID); err != nil {
+		err = ErrUserNotFound
+		return err
+	}
+	var pending bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM lawyer_verifications WHERE user_id = ? AND status = ?)", v.UserID, LawyerPending).Scan(&pending)
+	if err != nil {
+		return err
+	}
+	if pending {
+		err = ErrVerificationPending
+		return err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO lawyer_verifications (user_id, license_number, law_firm, jurisdiction, status) VALUES (?, ?, ?, ?, ?)",
+		v.UserID, v.LicenseNumber, v.LawFirm, v.Jurisdiction, LawyerPending,
+	)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	v.ID = int(id)
+	v.Status = LawyerPending
+
+	for _, doc := range v.Documents {
+		if _, err = tx.Exec(
+			"INSERT INTO lawyer_verification_documents (verification_id, name, url) VALUES (?, ?, ?)",
+			v.ID, doc.Name, doc.URL,
+		); err != nil {
+			return err
+		}
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// ListLawyerVerifications 分页查询认证申请，status 为空表示不限；待审核的申请按提交时间正序（先提交先审核），其余按时间倒序
+func ListLawyerVerifications(status string, page Pagination) ([]LawyerVerification, int, error) {
+	page.Normalize()
+
+	where := ""
+	var args []interface{}
+	if status != "" {
+		where = " WHERE v.status = ?"
+		args = append(args, status)
+	}
+	order := " ORDER BY v.id DESC"
+	if status == LawyerPending {
+		order = " ORDER BY v.id ASC"
+	}
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM lawyer_verifications v"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT "+lawyerVerificationColumns+" FROM lawyer_verifications v JOIN users u ON u.id = v.user_id"+where+order+" LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []LawyerVerification{}
+	for rows.Next() {
+		v, err := scanLawyerVerification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *v)
+	}
+	return list, total, rows.Err()
+}
+
+// ApproveLawyerVerification 通过认证申请：授予认证律师角色，之前通过的认证随之过期，并通知申请人
+func ApproveLawyerVerification(id, reviewerID int, expiresAt *time.Time, note string) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	userID, err := lockPendingVerification(tx, id, reviewerID)
+	if err != nil {
+		return err
+	}
+
+	// 重新认证（如更换执业机构）时，旧的认证由新的认证取代
+	if _, err = tx.Exec(
+		"UPDATE lawyer_verifications SET status = ? WHERE user_id = ? AND status = ?",
+		LawyerExpired, userID, LawyerApproved,
+	); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(
+		"UPDATE lawyer_verifications SET status = ?, review_note = ?, reviewed_by = ?, reviewed_at = NOW(), expires_at = ? WHERE id = ?",
+		LawyerApproved, note, reviewerID, expiresAt, id,
+	); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("INSERT IGNORE INTO user_roles (user_id, role_id) VALUES (?, ?)", userID, RoleLawyer); err != nil {
+		return err
+	}
+
+	message := "您的律师认证已通过审核"
+	if expiresAt != nil {
+		message += "，有效期至 " + expiresAt.Format("2006-01-02")
+	}
+	if err = createNotification(tx, userID, NotificationLawyerApproved, message); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// RejectLawyerVerification 驳回认证申请并通知申请人
+func RejectLawyerVerification(id, reviewerID int, note string) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	userID, err := lockPendingVerification(tx, id, reviewerID)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"UPDATE lawyer_verifications SET status = ?, review_note = ?, reviewed_by = ?, reviewed_at = NOW() WHERE id = ?",
+		LawyerRejected, note, reviewerID, id,
+	); err != nil {
+		return err
+	}
+	if err = createNotification(tx, userID, NotificationLawyerRejected, "您的律师认证未通过审核，审核意见："+note); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// lockPendingVerification 锁定待审核的申请并返回申请人ID，审核人不能审核自己的申请
+func lockPendingVerification(tx *sql.Tx, id, reviewerID int) (int, error) {
+	var userID int
+	var status string
+	err := tx.QueryRow("SELECT user_id, status FROM lawyer_verifications WHERE id = ? FOR UPDATE", id).Scan(&userID, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrVerificationNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if status != LawyerPending {
+		return 0, ErrVerificationReviewed
+	}
+	if userID == reviewerID {
+		return 0, ErrVerificationSelf
+	}
+	return userID, nil
+}
+
+// ExpireLawyerVerifications 将超过有效期的认证标记为已过期，收回认证律师角色并通知用户，返回过期的认证数
+func ExpireLawyerVerifications() (int, error) {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.Query(
+		"SELECT id, user_id FROM lawyer_verifications WHERE status = ? AND expires_at <= NOW() FOR UPDATE",
+		LawyerApproved,
+	)
+	if err != nil {
+		return 0, err
+	}
+	expired := make(map[int]int)
+	for rows.Next() {
+		var id, userID int
+		if err = rows.Scan(&id, &userID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		expired[id] = userID
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, userID := range expired {
+		if _, err = tx.Exec("UPDATE lawyer_verifications SET status = ? WHERE id = ?", LawyerExpired, id); err != nil {
+			return 0, err
+		}
+		if _, err = tx.Exec("DELETE FROM user_roles WHERE user_id = ? AND role_id = ?", userID, RoleLawyer); err != nil {
+			return 0, err
+		}
+		if err = createNotification(tx, userID, NotificationLawyerExpired, "您的律师认证已过期，请重新提交认证申请"); err != nil {
+			return 0, err
+		}
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return len(expired), err
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 登录结果原因
+const (
+	LoginSuccess        = "success"         // 登录成功
+	LoginBadPassword    = "bad_password"    // 密码错误
+	LoginUnknownAccount = "unknown_account" // 账号不存在
+	LoginLocked         = "locked"          // 账号或IP已锁定
+	LoginMFARequired    = "mfa_required"    // 密码正确，等待两步验证
+	LoginBadMFACode     = "bad_mfa_code"    // 两步验证码错误
+	LoginBanned         = "banned"          // 账号已封禁
+	LoginResetRequired  = "reset_required"  // 管理员要求重置密码
+)
+
+// 登录失败计数的范围
+const (
+	LockoutScopeAccount = "account" // 按账号计数
+	LockoutScopeIP      = "ip"      // 按来源IP计数
+)
+
+// LoginAttempt 登录审计记录
+type LoginAttempt struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"user_id"` // 账号不存在时为空
+	Account   string    `json:"account"` // 登录时填写的用户名或邮箱
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginAttemptFilter 登录审计查询条件，零值表示不限
+type LoginAttemptFilter struct {
+	UserID  int
+	Account string
+	IP      string
+}
+
+// LoginLockout 登录失败计数和锁定状态
+type LoginLockout struct {
+	Failures     int        // 连续失败次数
+	LockedUntil  *time.Time // 锁定截止时间
+	LastFailedAt time.Time  // 最近一次失败时间
+}
+
+// RecordLoginAttempt 记录一次登录尝试
+func RecordLoginAttempt(attempt *LoginAttempt) error {
+	_, err := DB.Exec(
+		"INSERT INTO login_attempts (user_id, account, ip, user_agent, success, reason) VALUES (?, ?, ?, ?, ?, ?)",
+		attempt.UserID, attempt.Account, attempt.IP, attempt.UserAgent, attempt.Success, attempt.Reason,
+	)
+	return err
+}
+
+// ListLoginAttempts 分页查询登录审计记录，按时间倒序
+func ListLoginAttempts(filter LoginAttemptFilter, page Pagination) ([]LoginAttempt, int, error) {
+	page.Normalize()
+
+	var conditions []string
+	var args []interface{}
+	if filter.UserID > 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Account != "" {
+		conditions = append(conditions, "account = ?")
+		args = append(args, filter.Account)
+	}
+	if filter.IP != "" {
+		conditions = append(conditions, "ip = ?")
+		args = append(args, filter.IP)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM login_attempts"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT id, user_id, account, ip, user_agent, success, reason, created_at FROM login_attempts"+where+
+			" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	attempts := []LoginAttempt{}
+	for rows.Next() {
+		var a LoginAttempt
+		var userID sql.NullInt64
+		if err := rows.Scan(&a.ID, &userID, &a.Account, &a.IP, &a.UserAgent, &a.Success, &a.Reason, &a.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			id := int(userID.Int64)
+			a.UserID = &id
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, total, rows.Err()
+}
+
+// GetLoginLockout 获取失败计数，没有记录时返回 nil
+func GetLoginLockout(scope, key string) (*LoginLockout, error) {
+	lockout := &LoginLockout{}
+	err := DB.QueryRow(
+		"SELECT failures, locked_until, last_failed_at FROM login_lockouts WHERE scope = ? AND lock_key = ?",
+		scope, key,
+	).Scan(&lockout.Failures, &lockout.LockedUntil, &lockout.LastFailedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lockout, nil
+}
+
+// RecordLoginFailure 失败计数加一并按 lockFor 计算锁定时长，距上次失败超过 window 时重新计数
+func RecordLoginFailure(scope, key string, now time.Time, window time.Duration, lockFor func(failures int) time.Duration) (*LoginLockout, error) {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定计数行
+	lockout := &LoginLockout{}
+	err = tx.QueryRow(
+		"SELECT failures, locked_until, last_failed_at FROM login_lockouts WHERE scope = ? AND lock_key = ? FOR UPDATE",
+		scope, key,
+	).Scan(&lockout.Failures, &lockout.LockedUntil, &lockout.LastFailedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 距上次失败太久时重新计数
+	if now.Sub(lockout.LastFailedAt) > window {
+		lockout.Failures = 0
+		lockout.LockedUntil = nil
+	}
+	lockout.Failures++
+	lockout.LastFailedAt = now
+	if d := lockFor(lockout.Failures); d > 0 {
+		until := now.Add(d)
+		lockout.LockedUntil = &until
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO login_lockouts (scope, lock_key, failures, locked_until, last_failed_at) VALUES (?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE failures = VALUES(failures), locked_until = VALUES(locked_until), last_failed_at = VALUES(last_failed_at)",
+		scope, key, lockout.Failures, lockout.LockedUntil, lockout.LastFailedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return lockout, err
+}
+
+// ResetLoginFailures 清除失败计数和锁定状态
+func ResetLoginFailures(scope, key string) error {
+	_, err := DB.Exec("DELETE FROM login_lockouts WHERE scope = ? AND lock_key = ?", scope, key)
+	return err
+}
+
+// DecayLoginFailures 失败计数减一，不解除已生效的锁定
+func DecayLoginFailures(scope, key string) error {
+	_, err := DB.Exec("UPDATE login_lockouts SET failures = failures - 1 WHERE scope = ? AND lock_key = ? AND failures > 0", scope, key)
+	return err
+}
+
+// DeleteExpiredLoginLockouts 清理最近一次失败早于 before 且已解除锁定的计数
+func DeleteExpiredLoginLockouts(before time.Time) (int64, error) {
+	result, err := DB.Exec(
+		"DELETE FROM login_lockouts WHERE last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)",
+		before, before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// TOTPState 用户的两步验证状态
+type TOTPState struct {
+	Secret   string // base32 编码的密钥，未绑定时为空
+	Enabled  bool   // 是否已启用
+	LastStep int64  // 最近一次验证通过的时间步
+}
+
+// GetTOTPState 获取用户的两步验证状态
+func GetTOTPState(userID int) (*TOTPState, error) {
+	state := &TOTPState{}
+	var secret sql.NullString
+	err := DB.QueryRow(
+		"SELECT totp_secret, totp_enabled, totp_last_step FROM users WHERE id = ?", userID,
+	).Scan(&secret, &state.Enabled, &state.LastStep)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	state.Secret = secret.String
+	return state, nil
+}
+
+// SetTOTPSecret 保存待确认的 TOTP 密钥，已启用两步验证时不会覆盖
+func SetTOTPSecret(userID int, secret string) error {
+	result, err := DB.Exec(
+		"UPDATE users SET totp_secret = ?, totp_last_step = 0 WHERE id = ? AND totp_enabled = 0",
+		secret, userID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("已启用两步验证")
+	}
+	return nil
+}
+
+// EnableTOTP 启用两步验证，记录本次验证的时间步并保存恢复码哈希
+func EnableTOTP(userID int, step int64, codeHashes []string) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec(
+		"UPDATE users SET totp_enabled = 1, totp_last_step = ? WHERE id = ? AND totp_secret IS NOT NULL",
+		step, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if err = replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// DisableTOTP 关闭两步验证，清除密钥和全部恢复码
+func DisableTOTP(userID int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec(
+		"UPDATE users SET totp_secret = NULL, totp_enabled = 0, totp_last_step = 0 WHERE id = ?", userID,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// RecordTOTPStep 记录验证通过的时间步，时间步不大于已记录的值时返回 false（验证码被重放）
+func RecordTOTPStep(userID int, step int64) (bool, error) {
+	result, err := DB.Exec(
+		"UPDATE users SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?",
+		step, userID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ReplaceRecoveryCodes 用新的恢复码替换用户原有的全部恢复码
+func ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// replaceRecoveryCodes 在事务中删除旧恢复码并写入新恢复码
+func replaceRecoveryCodes(ex execer, userID int, codeHashes []string) error {
+	if _, err := ex.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := ex.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode 使用一个恢复码，恢复码不存在或已使用时返回 false
+func UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	result, err := DB.Exec(
+		"UPDATE recovery_codes SET used_at = NOW() WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		userID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// CountRecoveryCodes 统计用户剩余可用的恢复码数量
+func CountRecoveryCodes(userID int) (int, error) {
+	var count int
+	err := DB.QueryRow(
+		"SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL", userID,
+	).Scan(&count)
+	return count, err
+}
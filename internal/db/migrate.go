@@ -0,0 +1,161 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// migration 一次幂等的表结构迁移：schema.sql 中的 CREATE TABLE IF NOT EXISTS 不会修改已存在的表，
+// 旧数据库缺少的列、索引和外键由迁移补齐；pending 返回 true 时按顺序执行 statements
+type migration struct {
+	name       string
+	pending    func() (bool, error)
+	statements []string
+}
+
+// migrations 按顺序执行的迁移，新增列时同时修改 schema.sql 中的建表语句
+var migrations = []migration{
+	// 文章封面
+	addColumn("articles", "cover_url", "VARCHAR(512) NOT NULL DEFAULT '' COMMENT '封面图片地址，为空时取正文第一张图片'"),
+
+	// 全文搜索
+	addIndex("articles", "ft_title", "FULLTEXT INDEX ft_title (title) WITH PARSER ngram"),
+	addIndex("articles", "ft_title_content", "FULLTEXT INDEX ft_title_content (title, content) WITH PARSER ngram"),
+	addIndex("comments", "ft_content", "FULLTEXT INDEX ft_content (content) WITH PARSER ngram"),
+
+	// 评论回复
+	addColumn("comments", "parent_id", "INT DEFAULT NULL COMMENT '回复的父评论ID，为空表示顶层评论'"),
+	addIndex("comments", "idx_parent_id", "INDEX idx_parent_id (parent_id)"),
+	addForeignKey("comments", "parent_id", "FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE"),
+
+	// 评论审核
+	addColumn("comments", "review_reason", "VARCHAR(255) NOT NULL DEFAULT '' COMMENT '审核意见'"),
+	addColumn("comments", "reviewed_by", "INT DEFAULT NULL COMMENT '审核管理员ID'"),
+	addColumn("comments", "reviewed_at", "TIMESTAMP NULL DEFAULT NULL COMMENT '审核时间'"),
+	addIndex("comments", "idx_visible_created", "INDEX idx_visible_created (is_visible, created_at)"),
+
+	// 评论编辑和删除
+	addColumn("comments", "edited_at", "TIMESTAMP NULL DEFAULT NULL COMMENT '最后编辑时间'"),
+	addColumn("comments", "deleted_at", "TIMESTAMP NULL DEFAULT NULL COMMENT '删除时间'"),
+
+	// 退出所有设备
+	addColumn("users", "tokens_valid_after",
+		"TIMESTAMP(3) NULL DEFAULT NULL COMMENT '此时间及之前签发的令牌全部失效（退出所有设备），精确到毫秒，由应用写入'"),
+
+	// 两步验证
+	addColumn("users", "totp_secret", "VARCHAR(64) DEFAULT NULL COMMENT 'TOTP 密钥（base32），开始绑定后生成'"),
+	addColumn("users", "totp_enabled", "TINYINT NOT NULL DEFAULT 0 COMMENT '是否启用两步验证：0-未启用，1-已启用'"),
+	addColumn("users", "totp_last_step", "BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次验证通过的时间步，防止验证码重放'"),
+
+	// 邮箱验证
+	addColumn("users", "email_verified",
+		"TINYINT NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证：0-未验证（只读），1-已验证'",
+		// 启用邮箱验证前注册的用户视为已验证，避免被只读限制锁住
+		"UPDATE users SET email_verified = 1"),
+
+	// 用户管理
+	addColumn("users", "must_reset_password", "TINYINT NOT NULL DEFAULT 0 COMMENT '管理员要求重置密码：0-否，1-是（重置前不能登录）'"),
+	addColumn("users", "banned_at", "TIMESTAMP NULL DEFAULT NULL COMMENT '封禁时间，为空表示未封禁'"),
+	addColumn("users", "banned_until", "TIMESTAMP NULL DEFAULT NULL COMMENT '封禁截止时间，为空表示永久封禁'"),
+	addColumn("users", "ban_reason", "VARCHAR(255) NOT NULL DEFAULT '' COMMENT '封禁原因'"),
+	addColumn("users", "created_at", "TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '注册时间'"),
+
+	// 分类发布权限
+	addColumn("categories", "publish_permission",
+		"VARCHAR(50) DEFAULT NULL COMMENT '在该分类及其子分类下发布文章所需的权限，为空时继承父分类'"),
+
+	// 问答悬赏积分
+	addColumn("users", "points", "INT NOT NULL DEFAULT 100 COMMENT '积分，用于问答悬赏，注册时赠送 100'"),
+
+	// 文章适用地区
+	addColumn("articles", "region_code",
+		"VARCHAR(12) NOT NULL DEFAULT '' COMMENT '适用地区的行政区划代码（地方政策、线下活动等），为空表示不限地区'"),
+	addIndex("articles", "idx_region_code", "INDEX idx_region_code (region_code)"),
+
+	// 用户名唯一
+	addIndex("users", "username", "UNIQUE KEY username (username)",
+		// 重名账号中最早注册的保留原用户名，其余改为“用户名_ID”
+		"UPDATE users u JOIN (SELECT username, MIN(id) AS keep_id FROM users GROUP BY username HAVING COUNT(*) > 1) d "+
+			"ON u.username = d.username AND u.id <> d.keep_id "+
+			"SET u.username = CONCAT(LEFT(u.username, 38), '_', u.id)"),
+}
+
+// addColumn 列不存在时新增列，新增后执行 after（如回填已有数据）
+func addColumn(table, column, definition string, after ...string) migration {
+	return migration{
+		name: fmt.Sprintf("新增列 %s.%s", table, column),
+		pending: func() (bool, error) {
+			found, err := columnExists(table, column)
+			return !found, err
+		},
+		statements: append([]string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)}, after...),
+	}
+}
+
+// addIndex 索引不存在时新增索引（含全文索引），新增前先执行 before（如处理违反唯一约束的旧数据）
+func addIndex(table, index, definition string, before ...string) migration {
+	return migration{
+		name: fmt.Sprintf("新增索引 %s.%s", table, index),
+		pending: func() (bool, error) {
+			found, err := exists(
+				"SELECT COUNT(*) FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?",
+				table, index,
+			)
+			return !found, err
+		},
+		statements: append(before, fmt.Sprintf("ALTER TABLE %s ADD %s", table, definition)),
+	}
+}
+
+// addForeignKey 列上没有外键时新增外键
+func addForeignKey(table, column, definition string) migration {
+	return migration{
+		name: fmt.Sprintf("新增外键 %s.%s", table, column),
+		pending: func() (bool, error) {
+			found, err := exists(
+				"SELECT COUNT(*) FROM information_schema.KEY_COLUMN_USAGE "+
+					"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL",
+				table, column,
+			)
+			return !found, err
+		},
+		statements: []string{fmt.Sprintf("ALTER TABLE %s ADD %s", table, definition)},
+	}
+}
+
+// columnExists 查询当前数据库的表中是否有指定列
+func columnExists(table, column string) (bool, error) {
+	return exists(
+		"SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+		table, column,
+	)
+}
+
+// exists 执行 COUNT 查询，结果大于 0 时返回 true
+func exists(query string, args ...interface{}) (bool, error) {
+	var count int
+	if err := DB.QueryRow(query, args...).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// migrate 依次执行尚未完成的迁移
+func migrate() error {
+	for _, m := range migrations {
+		pending, err := m.pending()
+		if err != nil {
+			return fmt.Errorf("检查迁移（%s）失败: %v", m.name, err)
+		}
+		if !pending {
+			continue
+		}
+		for _, statement := range m.statements {
+			if _, err := DB.Exec(statement); err != nil {
+				return fmt.Errorf("执行迁移（%s）失败: %v", m.name, err)
+			}
+		}
+		log.Printf("已执行数据库迁移：%s", m.name)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// PendingComment 待审核评论，附带文章标题和作者用户名便于审核
+type PendingComment struct {
+	Comment
+	ArticleTitle string `json:"article_title"`
+	Username     string `json:"username"`
+}
+
+// ListPendingComments 分页获取待审核评论，按提交时间正序（先提交先审核）
+func ListPendingComments(page Pagination) ([]PendingComment, int, error) {
+	page.Normalize()
+
+	var total int
+	err := DB.QueryRow("SELECT COUNT(*) FROM comments WHERE is_visible = ?", CommentPending).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT c.id, c.article_id, c.content, c.created_at, c.is_visible, c.likes, c.user_id, c.parent_id, a.title, u.username "+
+			"FROM comments c JOIN articles a ON a.id = c.article_id JOIN users u ON u.id = c.user_id "+
+			"WHERE c.is_visible = ? ORDER BY c.created_at ASC, c.id ASC LIMIT ? OFFSET ?",
+		CommentPending, page.PageSize, page.Offset(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	comments := []PendingComment{}
+	for rows.Next() {
+		var pc PendingComment
+		var parentID sql.NullInt64
+		if err := rows.Scan(&pc.ID, &pc.ArticleID, &pc.Content, &pc.CreatedAt, &pc.IsVisible, &pc.Likes, &pc.UserID, &parentID,
+			&pc.ArticleTitle, &pc.Username); err != nil {
+			return nil, 0, err
+		}
+		if parentID.Valid {
+			pid := int(parentID.Int64)
+			pc.ParentID = &pid
+		}
+		comments = append(comments, pc)
+	}
+	return comments, total, rows.Err()
+}
+
+// CountApprovedComments 统计用户已通过审核的评论数
+func CountApprovedComments(userID int) (int, error) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM comments WHERE user_id = ? AND is_visible = ?", userID, CommentVisible).Scan(&count)
+	return count, err
+}
+
+// ReviewComments 批量审核评论，approve 为 true 表示通过，否则驳回
+// 同步调整文章评论计数并通知评论作者，返回实际改变状态的评论数
+func ReviewComments(commentIDs []int, approve bool, reason string, reviewerID int) (int, error) {
+	status := CommentRejected
+	notificationType := NotificationCommentRejected
+	if approve {
+		status = CommentVisible
+		notificationType = NotificationCommentApproved
+	}
+
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	reviewed := 0
+	for _, commentID := range commentIDs {
+		// 锁定评论，读取当前状态
+		var articleID, userID, oldStatus int
+		err = tx.QueryRow("SELECT article_id, user_id, is_visible FROM comments WHERE id = ? FOR UPDATE", commentID).
+			Scan(&articleID, &userID, &oldStatus)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if oldStatus == status || oldStatus == CommentDeleted {
+			continue
+		}
+
+		// 更新审核状态
+		_, err = tx.Exec(
+			"UPDATE comments SET is_visible = ?, review_reason = ?, reviewed_by = ?, reviewed_at = NOW() WHERE id = ?",
+			status, reason, reviewerID, commentID,
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		// 评论计数只统计已通过审核的评论
+		if err = adjustCommentCount(tx, articleID, oldStatus, status); err != nil {
+			return 0, err
+		}
+
+		// 通知评论作者
+		if err = createNotification(tx, userID, notificationType, reviewMessage(commentID, approve, reason)); err != nil {
+			return 0, err
+		}
+		reviewed++
+	}
+
+	// 提交事务
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return reviewed, nil
+}
+
+// reviewMessage 生成审核结果通知内容
+func reviewMessage(commentID int, approve bool, reason string) string {
+	message := fmt.Sprintf("您的评论（ID: %d）", commentID)
+	if approve {
+		message += "已通过审核"
+	} else {
+		message += "未通过审核"
+	}
+	if reason != "" {
+		message += "，审核意见：" + reason
+	}
+	return message
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// 通知类型
+const (
+	NotificationCommentApproved = "comment_approved" // 评论审核通过
+	NotificationCommentRejected = "comment_rejected" // 评论审核未通过
+	NotificationLawyerApproved  = "lawyer_approved"  // 律师认证通过
+	NotificationLawyerRejected  = "lawyer_rejected"  // 律师认证未通过
+	NotificationLawyerExpired   = "lawyer_expired"   // 律师认证已过期
+	NotificationAnswerAccepted  = "answer_accepted"  // 回答被采纳
+)
+
+// Notification 通知数据模型
+type Notification struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	Content   string    `json:"content"`
+	IsRead    bool      `json:"is_read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// execer 可执行 SQL 的对象（*sql.DB 或 *sql.Tx）
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryer 可执行查询的对象（*sql.DB 或 *sql.Tx）
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// createNotification 为用户创建一条通知，可在事务中调用
+func createNotification(ex execer, userID int, notificationType, content string) error {
+	_, err := ex.Exec("INSERT INTO notifications (user_id, type, content) VALUES (?, ?, ?)", userID, notificationType, content)
+	return err
+}
+
+// GetNotifications 分页获取用户的通知，按时间倒序，同时返回未读数量
+func GetNotifications(userID int, page Pagination) ([]Notification, int, error) {
+	page.Normalize()
+
+	var unread int
+	err := DB.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND is_read = 0", userID).Scan(&unread)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT id, type, content, is_read, created_at FROM notifications WHERE user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+		userID, page.PageSize, page.Offset(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.Type, &n.Content, &n.IsRead, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, unread, rows.Err()
+}
+
+// MarkNotificationsRead 将用户的全部通知标记为已读
+func MarkNotificationsRead(userID int) error {
+	_, err := DB.Exec("UPDATE notifications SET is_read = 1 WHERE user_id = ? AND is_read = 0", userID)
+	return err
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 分页默认值
+const (
+	DefaultPageSize = 20    // 默认每页条数
+	MaxPageSize     = 100   // 每页最大条数
+	MaxOffset       = 10000 // 偏移分页最多跳过的行数，更深的翻页需使用游标分页
+)
+
+// 分页错误
+var (
+	ErrInvalidCursor = errors.New("无效的分页游标")
+	ErrPageTooDeep   = errors.New("页码过大，请使用游标分页")
+)
+
+// Pagination 分页参数：Cursor 非空时使用游标分页，否则按 Page 偏移分页
+type Pagination struct {
+	Page     int
+	PageSize int
+	Cursor   string
+}
+
+// Normalize 修正越界的分页参数，页码超过最大偏移量对应的页数时截断为最后一页
+func (p *Pagination) Normalize() {
+	p.PageSize = p.size()
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if last := p.maxPage(); p.Page > last {
+		p.Page = last
+	}
+}
+
+// Validate 检查页码是否超过最大偏移量，须在 Normalize 截断页码之前调用
+// 无论是否使用游标都会检查，避免携带游标绕过偏移量限制
+func (p Pagination) Validate() error {
+	if p.Page > p.maxPage() {
+		return ErrPageTooDeep
+	}
+	return nil
+}
+
+// Offset 偏移分页时跳过的行数，页码越界时截断到 [0, MaxOffset]
+func (p Pagination) Offset() int {
+	page := p.Page
+	if page < 1 {
+		page = 1
+	}
+	if last := p.maxPage(); page > last {
+		page = last
+	}
+	return (page - 1) * p.size()
+}
+
+// size 修正后的每页条数
+func (p Pagination) size() int {
+	if p.PageSize < 1 {
+		return DefaultPageSize
+	}
+	if p.PageSize > MaxPageSize {
+		return MaxPageSize
+	}
+	return p.PageSize
+}
+
+// maxPage 偏移量不超过 MaxOffset 的最大页码，先除后乘避免超大页码相乘溢出
+func (p Pagination) maxPage() int {
+	return MaxOffset/p.size() + 1
+}
+
+// ArticleSort 文章列表排序方式
+type ArticleSort string
+
+// 文章排序方式常量
+const (
+	SortByLatest   ArticleSort = "latest"   // 按发布时间倒序
+	SortByLikes    ArticleSort = "likes"    // 按点赞数倒序
+	SortByComments ArticleSort = "comments" // 按评论数倒序
+)
+
+// articleSortColumns 排序方式对应的排序列，排序时总是以 id 作为第二排序键
+var articleSortColumns = map[ArticleSort]string{
+	SortByLatest:   "created_at",
+	SortByLikes:    "likes",
+	SortByComments: "comment_count",
+}
+
+// Valid 检查排序方式是否受支持
+func (s ArticleSort) Valid() bool {
+	_, ok := articleSortColumns[s]
+	return ok
+}
+
+// articleCursor 游标内容：排序方式、最后一行的排序键值与文章ID
+type articleCursor struct {
+	Sort  ArticleSort `json:"s"`
+	Value int64       `json:"v"`
+	ID    int         `json:"i"`
+}
+
+// encodeArticleCursor 根据当前页最后一篇文章生成下一页游标
+func encodeArticleCursor(sort ArticleSort, last ArticleSummary) string {
+	cur := articleCursor{Sort: sort, ID: last.ID}
+	switch sort {
+	case SortByLatest:
+		cur.Value = last.CreatedAt.Unix()
+	case SortByLikes:
+		cur.Value = int64(last.Likes)
+	case SortByComments:
+		cur.Value = int64(last.CommentCount)
+	}
+
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeArticleCursor 解析游标，返回排序键参数值与文章ID
+func decodeArticleCursor(sort ArticleSort, cursor string) (interface{}, int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+
+	var cur articleCursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.Sort != sort || cur.ID <= 0 {
+		return nil, 0, ErrInvalidCursor
+	}
+
+	if sort == SortByLatest {
+		return time.Unix(cur.Value, 0), cur.ID, nil
+	}
+	return cur.Value, cur.ID, nil
+}
+
+// ArticlePage 一页文章列表
+type ArticlePage struct {
+	List       []ArticleSummary `json:"list"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page,omitempty"` // 游标分页时不返回
+	PageSize   int              `json:"page_size"`
+	NextCursor string           `json:"next_cursor"` // 为空表示没有下一页
+}
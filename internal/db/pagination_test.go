@@ -0,0 +1,125 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestArticleCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 15, 8, 30, 45, 0, time.Local)
+	last := ArticleSummary{ID: 7, CreatedAt: created, Likes: 12, CommentCount: 3}
+
+	tests := []struct {
+		sort      ArticleSort
+		wantValue interface{}
+	}{
+		{SortByLatest, created},
+		{SortByLikes, int64(12)},
+		{SortByComments, int64(3)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			cursor := encodeArticleCursor(tt.sort, last)
+			value, id, err := decodeArticleCursor(tt.sort, cursor)
+			if err != nil {
+				t.Fatalf("decodeArticleCursor: %v", err)
+			}
+			if id != last.ID {
+				t.Errorf("id = %d, want %d", id, last.ID)
+			}
+			if want, ok := tt.wantValue.(time.Time); ok {
+				if got, _ := value.(time.Time); !got.Equal(want) {
+					t.Errorf("value = %v, want %v", value, want)
+				}
+			} else if value != tt.wantValue {
+				t.Errorf("value = %v, want %v", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestDecodeArticleCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	likes := encodeArticleCursor(SortByLikes, ArticleSummary{ID: 1, Likes: 5})
+
+	tests := []struct {
+		name   string
+		sort   ArticleSort
+		cursor string
+	}{
+		{"not base64", SortByLikes, "!!!"},
+		{"padded base64", SortByLikes, likes + "=="},
+		{"not json", SortByLikes, encode("not json")},
+		{"sort mismatch", SortByComments, likes},
+		{"missing id", SortByLikes, encode(`{"s":"likes","v":5}`)},
+		{"negative id", SortByLikes, encode(`{"s":"likes","v":5,"i":-1}`)},
+		{"wrong value type", SortByLikes, encode(`{"s":"likes","v":"5","i":1}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeArticleCursor(tt.sort, tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestPaginationNormalize(t *testing.T) {
+	tests := []struct {
+		in, want Pagination
+	}{
+		{Pagination{}, Pagination{Page: 1, PageSize: DefaultPageSize}},
+		{Pagination{Page: -3, PageSize: -1}, Pagination{Page: 1, PageSize: DefaultPageSize}},
+		{Pagination{Page: 4, PageSize: 1000}, Pagination{Page: 4, PageSize: MaxPageSize}},
+		{Pagination{Page: 2, PageSize: 10, Cursor: "c"}, Pagination{Page: 2, PageSize: 10, Cursor: "c"}},
+		{Pagination{Page: int(^uint(0) >> 1), PageSize: 20}, Pagination{Page: MaxOffset/20 + 1, PageSize: 20}},
+	}
+	for _, tt := range tests {
+		got := tt.in
+		got.Normalize()
+		if got != tt.want {
+			t.Errorf("Normalize(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPaginationValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    Pagination
+		wantErr error
+	}{
+		{"first page", Pagination{Page: 1, PageSize: 20}, nil},
+		{"at max offset", Pagination{Page: MaxOffset/20 + 1, PageSize: 20}, nil},
+		{"beyond max offset", Pagination{Page: MaxOffset/20 + 2, PageSize: 20}, ErrPageTooDeep},
+		{"overflowing page", Pagination{Page: int(^uint(0) >> 1), PageSize: MaxPageSize}, ErrPageTooDeep},
+		{"unnormalized page size", Pagination{Page: MaxOffset/DefaultPageSize + 2}, ErrPageTooDeep},
+		{"cursor does not bypass limit", Pagination{Page: MaxOffset, PageSize: 20, Cursor: "c"}, ErrPageTooDeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.page.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaginationOffset(t *testing.T) {
+	tests := []struct {
+		page Pagination
+		want int
+	}{
+		{Pagination{Page: 3, PageSize: 20}, 40},
+		{Pagination{Page: 0, PageSize: 20}, 0},
+		{Pagination{Page: MaxOffset, PageSize: 20}, MaxOffset},
+		{Pagination{Page: int(^uint(0) >> 1), PageSize: MaxPageSize}, MaxOffset},
+	}
+	for _, tt := range tests {
+		if got := tt.page.Offset(); got != tt.want {
+			t.Errorf("Offset(%+v) = %d, want %d", tt.page, got, tt.want)
+		}
+	}
+}
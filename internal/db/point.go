@@ -0,0 +1,91 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// 积分变动原因，对应 point_logs.reason
+const (
+	PointBountyHold     = "bounty_hold"     // 提问时扣除悬赏
+	PointBountyRefund   = "bounty_refund"   // 未采纳的问题删除时退还悬赏
+	PointBountyAward    = "bounty_award"    // 回答被采纳获得悬赏
+	PointAnswerAccepted = "answer_accepted" // 回答被采纳的奖励
+)
+
+// ErrInsufficientPoints 积分不足
+var ErrInsufficientPoints = errors.New("积分不足")
+
+// PointLog 积分变动记录
+type PointLog struct {
+	ID        int       `json:"id"`
+	Delta     int       `json:"delta"`
+	Balance   int       `json:"balance"`
+	Reason    string    `json:"reason"`
+	RefID     *int      `json:"ref_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// changePoints 在事务中修改用户积分并记录变动，扣除时余额不足返回 ErrInsufficientPoints
+func changePoints(tx *sql.Tx, userID, delta int, reason string, refID int) error {
+	if delta == 0 {
+		return nil
+	}
+
+	result, err := tx.Exec("UPDATE users SET points = points + ? WHERE id = ? AND points + ? >= 0", delta, userID, delta)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInsufficientPoints
+	}
+
+	var balance int
+	if err := tx.QueryRow("SELECT points FROM users WHERE id = ?", userID).Scan(&balance); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO point_logs (user_id, delta, balance, reason, ref_id) VALUES (?, ?, ?, ?, ?)",
+		userID, delta, balance, reason, refID,
+	)
+	return err
+}
+
+// ListPointLogs 分页查询用户的积分变动记录，按时间倒序
+func ListPointLogs(userID int, page Pagination) ([]PointLog, int, error) {
+	page.Normalize()
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM point_logs WHERE user_id = ?", userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT id, delta, balance, reason, ref_id, created_at FROM point_logs WHERE user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+		userID, page.PageSize, page.Offset(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []PointLog{}
+	for rows.Next() {
+		var log PointLog
+		var refID sql.NullInt64
+		if err := rows.Scan(&log.ID, &log.Delta, &log.Balance, &log.Reason, &refID, &log.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if refID.Valid {
+			id := int(refID.Int64)
+			log.RefID = &id
+		}
+		logs = append(logs, log)
+	}
+	return logs, total, rows.Err()
+}
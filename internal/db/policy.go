@@ -0,0 +1,299 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// 政策效力状态，对应 policies.status
+const (
+	PolicyEffective = "effective" // 现行有效
+	PolicyAmended   = "amended"   // 已修改
+	PolicyRepealed  = "repealed"  // 已废止
+)
+
+// PolicySort 政策列表排序方式
+type PolicySort string
+
+// 政策排序方式常量
+const (
+	PolicySortPublished PolicySort = "publish_date"   // 按发布日期倒序
+	PolicySortEffective PolicySort = "effective_date" // 按施行日期倒序
+	PolicySortLatest    PolicySort = "latest"         // 按收录时间倒序
+)
+
+// policySortColumns 排序方式对应的排序列，排序时总是以文章ID作为第二排序键
+var policySortColumns = map[PolicySort]string{
+	PolicySortPublished: "p.publish_date",
+	PolicySortEffective: "p.effective_date",
+	PolicySortLatest:    "a.created_at",
+}
+
+// Valid 检查排序方式是否受支持
+func (s PolicySort) Valid() bool {
+	_, ok := policySortColumns[s]
+	return ok
+}
+
+// 政策操作错误
+var (
+	ErrPolicyNotFound     = errors.New("政策不存在或已被删除")
+	ErrInterpretationSelf = errors.New("政策不能作为自身的解读")
+)
+
+// ValidPolicyStatus 检查政策效力状态是否有效
+func ValidPolicyStatus(status string) bool {
+	switch status {
+	case PolicyEffective, PolicyAmended, PolicyRepealed:
+		return true
+	}
+	return false
+}
+
+// PolicyMeta 政策的结构化信息，日期格式为 2006-01-02，适用地区取自文章的 region_code（为空表示全国）
+type PolicyMeta struct {
+	DocNumber     string  `json:"doc_number"`     // 发文字号
+	Authority     string  `json:"authority"`      // 发布机关
+	PublishDate   string  `json:"publish_date"`   // 发布日期
+	EffectiveDate *string `json:"effective_date"` // 施行日期
+	Status        string  `json:"status"`         // 效力状态
+}
+
+// Policy 政策详情
+type Policy struct {
+	Article
+	PolicyMeta
+}
+
+// PolicySummary 政策列表使用的投影
+type PolicySummary struct {
+	ArticleSummary
+	PolicyMeta
+}
+
+// PolicyFilter 政策查询条件，零值表示不限
+type PolicyFilter struct {
+	CategoryID    int        // 分类（含子分类）
+	DocNumber     string     // 发文字号，模糊匹配
+	Authority     string     // 发布机关，模糊匹配
+	RegionCode    string     // 适用地区（含下级地区）
+	NearRegion    string     // 与该地区相关：该地区、其上级地区和下级地区
+	Status        string     // 效力状态
+	PublishedFrom string     // 发布日期下限（含）
+	PublishedTo   string     // 发布日期上限（含）
+	EffectiveFrom string     // 施行日期下限（含）
+	EffectiveTo   string     // 施行日期上限（含）
+	InterpretedBy int        // 只看该文章解读的政策
+	Sort          PolicySort // 为空时按发布日期倒序
+}
+
+// policyMetaColumns 政策信息查询的列（政策表别名为 p），与 scan 的顺序一致
+const policyMetaColumns = "p.doc_number, p.authority, DATE_FORMAT(p.publish_date, '%Y-%m-%d'), " +
+	"DATE_FORMAT(p.effective_date, '%Y-%m-%d'), p.status"
+
+// scanDest 返回按 policyMetaColumns 顺序读取政策信息的目标，effectiveDate 读取后需调用 setEffectiveDate
+func (m *PolicyMeta) scanDest(effectiveDate *sql.NullString) []interface{} {
+	return []interface{}{&m.DocNumber, &m.Authority, &m.PublishDate, effectiveDate, &m.Status}
+}
+
+// setEffectiveDate 设置可为空的施行日期
+func (m *PolicyMeta) setEffectiveDate(effectiveDate sql.NullString) {
+	m.EffectiveDate = nil
+	if effectiveDate.Valid {
+		m.EffectiveDate = &effectiveDate.String
+	}
+}
+
+// CreatePolicy 发布政策：创建文章和政策信息
+func CreatePolicy(article *Article, meta *PolicyMeta) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = createArticle(tx, article); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(
+		"INSERT INTO policies (article_id, doc_number, authority, publish_date, effective_date, status) VALUES (?, ?, ?, ?, ?, ?)",
+		article.ID, meta.DocNumber, meta.Authority, meta.PublishDate, meta.EffectiveDate, meta.Status,
+	); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// UpdatePolicyMeta 修改政策的结构化信息
+func UpdatePolicyMeta(id int, meta *PolicyMeta) error {
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM policies WHERE article_id = ?)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrPolicyNotFound
+	}
+
+	_, err := DB.Exec(
+		"UPDATE policies SET doc_number = ?, authority = ?, publish_date = ?, effective_date = ?, status = ? WHERE article_id = ?",
+		meta.DocNumber, meta.Authority, meta.PublishDate, meta.EffectiveDate, meta.Status, id,
+	)
+	return err
+}
+
+// GetPolicy 获取可见政策的详情
+func GetPolicy(id int) (*Policy, error) {
+	article, err := GetArticleByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{Article: *article}
+	var effectiveDate sql.NullString
+	err = DB.QueryRow("SELECT "+policyMetaColumns+" FROM policies p WHERE p.article_id = ?", id).
+		Scan(policy.PolicyMeta.scanDest(&effectiveDate)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.setEffectiveDate(effectiveDate)
+	return policy, nil
+}
+
+// ListPolicies 按条件分页查询可见的政策
+func ListPolicies(filter PolicyFilter, page Pagination) ([]PolicySummary, int, error) {
+	page.Normalize()
+	if filter.Sort == "" {
+		filter.Sort = PolicySortPublished
+	}
+	column, ok := policySortColumns[filter.Sort]
+	if !ok {
+		return nil, 0, errors.New("不支持的排序方式")
+	}
+
+	conditions := []string{"a.is_visible = 1"}
+	var args []interface{}
+	if filter.CategoryID > 0 {
+		conditions = append(conditions, "a.category_id IN "+categorySubtreeSQL(1))
+		args = append(args, filter.CategoryID)
+	}
+	if q := strings.TrimSpace(filter.DocNumber); q != "" {
+		conditions = append(conditions, "p.doc_number LIKE ?")
+		args = append(args, "%"+escapeLike(q)+"%")
+	}
+	if q := strings.TrimSpace(filter.Authority); q != "" {
+		conditions = append(conditions, "p.authority LIKE ?")
+		args = append(args, "%"+escapeLike(q)+"%")
+	}
+	if filter.RegionCode != "" {
+		conditions = append(conditions, "a.region_code IN "+regionSubtreeSQL)
+		args = append(args, filter.RegionCode)
+	}
+	if filter.NearRegion != "" {
+		conditions = append(conditions, "(a.region_code IN "+regionSubtreeSQL+" OR a.region_code IN ("+regionAncestorsSQL+" SELECT code FROM region_path))")
+		args = append(args, filter.NearRegion, filter.NearRegion)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "p.status = ?")
+		args = append(args, filter.Status)
+	}
+	for _, r := range []struct{ cond, value string }{
+		{"p.publish_date >= ?", filter.PublishedFrom},
+		{"p.publish_date <= ?", filter.PublishedTo},
+		{"p.effective_date >= ?", filter.EffectiveFrom},
+		{"p.effective_date <= ?", filter.EffectiveTo},
+	} {
+		if r.value != "" {
+			conditions = append(conditions, r.cond)
+			args = append(args, r.value)
+		}
+	}
+	if filter.InterpretedBy > 0 {
+		conditions = append(conditions, "p.article_id IN (SELECT policy_id FROM policy_interpretations WHERE article_id = ?)")
+		args = append(args, filter.InterpretedBy)
+	}
+	from := " FROM policies p JOIN articles a ON a.id = p.article_id JOIN users u ON u.id = a.user_id WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT a.id, a.title, "+summaryContentSQL+", a.cover_url, a.created_at, a.likes, a.comment_count, a.category_id, a.region_code, a.user_id, u.username, "+
+			lawyerBadgeSQL("a.user_id")+", "+policyMetaColumns+from+" ORDER BY "+column+" DESC, a.id DESC LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []PolicySummary{}
+	for rows.Next() {
+		var p PolicySummary
+		var content string
+		var contentLength int
+		var effectiveDate sql.NullString
+		dest := []interface{}{&p.ID, &p.Title, &content, &contentLength, &p.CoverURL, &p.CreatedAt, &p.Likes, &p.CommentCount, &p.CategoryID, &p.RegionCode, &p.UserID,
+			&p.Author, &p.LawyerVerified}
+		if err := rows.Scan(append(dest, p.PolicyMeta.scanDest(&effectiveDate)...)...); err != nil {
+			return nil, 0, err
+		}
+		p.ArticleSummary = newArticleSummary(p.ArticleSummary, content, contentLength)
+		p.setEffectiveDate(effectiveDate)
+		list = append(list, p)
+	}
+	return list, total, rows.Err()
+}
+
+// ListPolicyInterpretations 分页查询政策的可见解读文章
+func ListPolicyInterpretations(policyID int, sort ArticleSort, page Pagination) (*ArticlePage, error) {
+	column, ok := articleSortColumns[sort]
+	if !ok {
+		return nil, errors.New("不支持的排序方式")
+	}
+	page.Normalize()
+	return listArticles(
+		" WHERE a.id IN (SELECT article_id FROM policy_interpretations WHERE policy_id = ?) AND a.is_visible = 1",
+		[]interface{}{policyID}, column, sort, page,
+	)
+}
+
+// AddPolicyInterpretation 将文章关联为政策的解读，重复关联不报错
+func AddPolicyInterpretation(policyID, articleID int) error {
+	if policyID == articleID {
+		return ErrInterpretationSelf
+	}
+
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM policies WHERE article_id = ?)", policyID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrPolicyNotFound
+	}
+
+	_, err := DB.Exec("INSERT IGNORE INTO policy_interpretations (policy_id, article_id) VALUES (?, ?)", policyID, articleID)
+	return err
+}
+
+// RemovePolicyInterpretation 取消文章与政策的解读关联
+func RemovePolicyInterpretation(policyID, articleID int) error {
+	_, err := DB.Exec("DELETE FROM policy_interpretations WHERE policy_id = ? AND article_id = ?", policyID, articleID)
+	return err
+}
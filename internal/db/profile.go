@@ -0,0 +1,82 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Profile 用户资料
+type Profile struct {
+	UserID      int    `json:"user_id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+	RegionCode  string `json:"region_code"` // 行政区划代码
+	Title       string `json:"title"`       // 职业头衔
+	// LawyerVerified 是否为认证律师
+	LawyerVerified bool `json:"lawyer_verified"`
+}
+
+// UserStats 用户的公开统计数据
+type UserStats struct {
+	Articles      int `json:"articles"`       // 发布的可见文章数
+	Comments      int `json:"comments"`       // 发表的可见评论数
+	LikesReceived int `json:"likes_received"` // 文章和评论获得的点赞数
+}
+
+// GetProfile 获取用户资料，尚未填写资料的用户返回空资料
+func GetProfile(userID int) (*Profile, error) {
+	profile := &Profile{}
+	var displayName, bio, avatarURL, regionCode, title sql.NullString
+	err := DB.QueryRow(
+		"SELECT u.id, u.username, p.display_name, p.bio, p.avatar_url, p.region_code, p.title, "+lawyerBadgeSQL("u.id")+
+			" FROM users u LEFT JOIN user_profiles p ON p.user_id = u.id WHERE u.id = ?",
+		userID,
+	).Scan(&profile.UserID, &profile.Username, &displayName, &bio, &avatarURL, &regionCode, &title, &profile.LawyerVerified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("用户不存在")
+	}
+	if err != nil {
+		return nil, err
+	}
+	profile.DisplayName = displayName.String
+	profile.Bio = bio.String
+	profile.AvatarURL = avatarURL.String
+	profile.RegionCode = regionCode.String
+	profile.Title = title.String
+	return profile, nil
+}
+
+// SaveProfile 保存用户资料
+func SaveProfile(profile *Profile) error {
+	_, err := DB.Exec(
+		"INSERT INTO user_profiles (user_id, display_name, bio, avatar_url, region_code, title) VALUES (?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE display_name = VALUES(display_name), bio = VALUES(bio), avatar_url = VALUES(avatar_url), "+
+			"region_code = VALUES(region_code), title = VALUES(title)",
+		profile.UserID, profile.DisplayName, profile.Bio, profile.AvatarURL, profile.RegionCode, profile.Title,
+	)
+	return err
+}
+
+// GetUserStats 统计用户的文章数、评论数和获得的点赞数
+func GetUserStats(userID int) (*UserStats, error) {
+	stats := &UserStats{}
+	var articleLikes, commentLikes int
+	err := DB.QueryRow(
+		"SELECT COUNT(*), IFNULL(SUM(likes), 0) FROM articles WHERE user_id = ? AND is_visible = ?",
+		userID, ArticleVisible,
+	).Scan(&stats.Articles, &articleLikes)
+	if err != nil {
+		return nil, err
+	}
+	err = DB.QueryRow(
+		"SELECT COUNT(*), IFNULL(SUM(likes), 0) FROM comments WHERE user_id = ? AND is_visible = ?",
+		userID, CommentVisible,
+	).Scan(&stats.Comments, &commentLikes)
+	if err != nil {
+		return nil, err
+	}
+	stats.LikesReceived = articleLikes + commentLikes
+	return stats, nil
+}
@@ -0,0 +1,311 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 问题状态筛选
+const (
+	QuestionUnanswered = "unanswered" // 还没有回答
+	QuestionUnsolved   = "unsolved"   // 未采纳回答
+	QuestionSolved     = "solved"     // 已采纳回答
+)
+
+// QuestionSort 问题列表排序方式
+type QuestionSort string
+
+// 问题排序方式常量
+const (
+	QuestionSortLatest  QuestionSort = "latest"  // 按提问时间倒序
+	QuestionSortBounty  QuestionSort = "bounty"  // 按悬赏积分倒序
+	QuestionSortAnswers QuestionSort = "answers" // 按回答数倒序
+)
+
+// questionSortColumns 排序方式对应的排序列，排序时总是以文章ID作为第二排序键
+var questionSortColumns = map[QuestionSort]string{
+	QuestionSortLatest:  "a.created_at",
+	QuestionSortBounty:  "q.bounty",
+	QuestionSortAnswers: "answer_count",
+}
+
+// Valid 检查排序方式是否受支持
+func (s QuestionSort) Valid() bool {
+	_, ok := questionSortColumns[s]
+	return ok
+}
+
+// 问答操作错误
+var (
+	ErrQuestionNotFound  = errors.New("问题不存在或已被删除")
+	ErrQuestionForbidden = errors.New("只有提问者可以采纳回答")
+	ErrAnswerAccepted    = errors.New("该问题已采纳回答")
+	ErrAnswerNotFound    = errors.New("回答不存在或未通过审核")
+	ErrAcceptOwnAnswer   = errors.New("不能采纳自己的回答")
+)
+
+// 回答即问题文章下已通过审核的顶层评论
+const (
+	// answerCountSQL 问题（文章别名 a）的回答数
+	answerCountSQL = "(SELECT COUNT(*) FROM comments ans WHERE ans.article_id = a.id AND ans.parent_id IS NULL AND ans.is_visible = 1)"
+	// answerExistsSQL 问题（文章别名 a）下存在满足附加条件的回答，需要拼接条件和右括号
+	answerExistsSQL = "EXISTS(SELECT 1 FROM comments ans WHERE ans.article_id = a.id AND ans.parent_id IS NULL AND ans.is_visible = 1"
+)
+
+// QuestionFilter 问题查询条件，零值表示不限
+type QuestionFilter struct {
+	CategoryID     int          // 分类（含子分类）
+	Status         string       // unanswered、unsolved 或 solved
+	LawyerAnswered bool         // 只看有认证律师回答的问题
+	Sort           QuestionSort // 为空时按提问时间倒序
+}
+
+// QuestionSummary 问题列表使用的投影
+type QuestionSummary struct {
+	ArticleSummary
+	Bounty            int  `json:"bounty"`
+	AnswerCount       int  `json:"answer_count"`
+	AcceptedCommentID *int `json:"accepted_comment_id"`
+	LawyerAnswered    bool `json:"lawyer_answered"` // 是否有认证律师回答
+}
+
+// Question 问题详情
+type Question struct {
+	Article
+	Bounty            int        `json:"bounty"`
+	AnswerCount       int        `json:"answer_count"`
+	AcceptedCommentID *int       `json:"accepted_comment_id"`
+	AcceptedAt        *time.Time `json:"accepted_at"`
+}
+
+// CreateQuestion 发布问题：创建文章和问题记录，并从提问者积分中扣除悬赏
+func CreateQuestion(article *Article, bounty int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = createArticle(tx, article); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("INSERT INTO questions (article_id, bounty) VALUES (?, ?)", article.ID, bounty); err != nil {
+		return err
+	}
+	if err = changePoints(tx, article.UserID, -bounty, PointBountyHold, article.ID); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// ListQuestions 按条件分页查询可见的问题
+func ListQuestions(filter QuestionFilter, page Pagination) ([]QuestionSummary, int, error) {
+	page.Normalize()
+	if filter.Sort == "" {
+		filter.Sort = QuestionSortLatest
+	}
+	column, ok := questionSortColumns[filter.Sort]
+	if !ok {
+		return nil, 0, errors.New("不支持的排序方式")
+	}
+
+	conditions := []string{"a.is_visible = 1"}
+	var args []interface{}
+	if filter.CategoryID > 0 {
+		conditions = append(conditions, "a.category_id IN "+categorySubtreeSQL(1))
+		args = append(args, filter.CategoryID)
+	}
+	switch filter.Status {
+	case QuestionUnanswered:
+		conditions = append(conditions, "NOT "+answerExistsSQL+")")
+	case QuestionUnsolved:
+		conditions = append(conditions, "q.accepted_comment_id IS NULL")
+	case QuestionSolved:
+		conditions = append(conditions, "q.accepted_comment_id IS NOT NULL")
+	}
+	if filter.LawyerAnswered {
+		conditions = append(conditions, answerExistsSQL+" AND "+lawyerBadgeSQL("ans.user_id")+")")
+	}
+	from := " FROM questions q JOIN articles a ON a.id = q.article_id WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT a.id, a.title, "+summaryContentSQL+", a.cover_url, a.created_at, a.likes, a.comment_count, a.category_id, a.region_code, a.user_id, "+
+			"(SELECT username FROM users WHERE id = a.user_id), "+lawyerBadgeSQL("a.user_id")+", "+
+			"q.bounty, "+answerCountSQL+" AS answer_count, q.accepted_comment_id, "+answerExistsSQL+" AND "+lawyerBadgeSQL("ans.user_id")+")"+
+			from+" ORDER BY "+column+" DESC, a.id DESC LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []QuestionSummary{}
+	for rows.Next() {
+		var q QuestionSummary
+		var content string
+		var contentLength int
+		var accepted sql.NullInt64
+		if err := rows.Scan(&q.ID, &q.Title, &content, &contentLength, &q.CoverURL, &q.CreatedAt, &q.Likes, &q.CommentCount, &q.CategoryID, &q.RegionCode, &q.UserID,
+			&q.Author, &q.ArticleSummary.LawyerVerified, &q.Bounty, &q.AnswerCount, &accepted, &q.LawyerAnswered); err != nil {
+			return nil, 0, err
+		}
+		q.ArticleSummary = newArticleSummary(q.ArticleSummary, content, contentLength)
+		if accepted.Valid {
+			id := int(accepted.Int64)
+			q.AcceptedCommentID = &id
+		}
+		list = append(list, q)
+	}
+	return list, total, rows.Err()
+}
+
+// GetQuestion 获取可见问题的详情
+func GetQuestion(id int) (*Question, error) {
+	article, err := GetArticleByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	question := &Question{Article: *article}
+	var accepted sql.NullInt64
+	var acceptedAt sql.NullTime
+	err = DB.QueryRow(
+		"SELECT q.bounty, "+answerCountSQL+", q.accepted_comment_id, q.accepted_at FROM questions q JOIN articles a ON a.id = q.article_id WHERE q.article_id = ?",
+		id,
+	).Scan(&question.Bounty, &question.AnswerCount, &accepted, &acceptedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if accepted.Valid {
+		cid := int(accepted.Int64)
+		question.AcceptedCommentID = &cid
+	}
+	if acceptedAt.Valid {
+		question.AcceptedAt = &acceptedAt.Time
+	}
+	return question, nil
+}
+
+// GetQuestionAnswers 获取问题的回答及其下的讨论，被采纳的回答排在最前，其余按得票（点赞数）倒序
+func GetQuestionAnswers(question *Question, maxDepth int) ([]*Comment, error) {
+	answers, err := GetCommentTree(question.ID, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := func(c *Comment) bool {
+		return question.AcceptedCommentID != nil && c.ID == *question.AcceptedCommentID
+	}
+	sort.SliceStable(answers, func(i, j int) bool {
+		if accepted(answers[i]) != accepted(answers[j]) {
+			return accepted(answers[i])
+		}
+		return answers[i].Likes > answers[j].Likes
+	})
+	return answers, nil
+}
+
+// AcceptAnswer 提问者采纳回答：向回答者发放悬赏和采纳奖励（reward）积分，并通知回答者
+func AcceptAnswer(questionID, commentID, userID, reward int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定问题，避免并发采纳
+	var askerID, bounty int
+	var accepted sql.NullInt64
+	err = tx.QueryRow(
+		"SELECT a.user_id, q.bounty, q.accepted_comment_id FROM questions q JOIN articles a ON a.id = q.article_id "+
+			"WHERE q.article_id = ? AND a.is_visible = 1 FOR UPDATE",
+		questionID,
+	).Scan(&askerID, &bounty, &accepted)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrQuestionNotFound
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if askerID != userID {
+		err = ErrQuestionForbidden
+		return err
+	}
+	if accepted.Valid {
+		err = ErrAnswerAccepted
+		return err
+	}
+
+	// 只能采纳该问题下已通过审核的顶层评论
+	var answererID int
+	err = tx.QueryRow(
+		"SELECT user_id FROM comments WHERE id = ? AND article_id = ? AND parent_id IS NULL AND is_visible = ?",
+		commentID, questionID, CommentVisible,
+	).Scan(&answererID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrAnswerNotFound
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if answererID == askerID {
+		err = ErrAcceptOwnAnswer
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"UPDATE questions SET accepted_comment_id = ?, accepted_at = NOW() WHERE article_id = ?",
+		commentID, questionID,
+	); err != nil {
+		return err
+	}
+	if err = changePoints(tx, answererID, bounty, PointBountyAward, questionID); err != nil {
+		return err
+	}
+	if err = changePoints(tx, answererID, reward, PointAnswerAccepted, questionID); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("您在问题（ID: %d）下的回答已被采纳", questionID)
+	if total := bounty + reward; total > 0 {
+		message += fmt.Sprintf("，获得 %d 积分", total)
+	}
+	if err = createNotification(tx, answererID, NotificationAnswerAccepted, message); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
@@ -0,0 +1,350 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// 权限代码
+const (
+	PermArticlePublish  = "article.publish"  // 发布文章
+	PermArticleModerate = "article.moderate" // 编辑、删除他人文章，审核文章
+	PermCommentModerate = "comment.moderate" // 删除他人评论，审核评论
+	PermCategoryManage  = "category.manage"  // 管理分类
+	PermFilterManage    = "filter.manage"    // 管理敏感词
+	PermPolicyEdit      = "policy.edit"      // 发布和编辑政策
+	PermEventManage     = "event.manage"     // 发布和管理线下活动
+	PermLawyerVerify    = "lawyer.verify"    // 审核律师认证
+	PermStatuteManage   = "statute.manage"   // 导入和管理法律法规
+)
+
+// RoleLawyer 认证律师角色，律师认证通过后自动授予，过期后收回
+const RoleLawyer = 6
+
+// 角色管理错误
+var (
+	ErrRoleNotFound      = errors.New("角色不存在")
+	ErrRoleNameTaken     = errors.New("角色名称已存在")
+	ErrSystemRole        = errors.New("系统角色不能删除或手动授予")
+	ErrAdminRole         = errors.New("管理员拥有全部权限，无需配置")
+	ErrUnknownPermission = errors.New("权限不存在")
+)
+
+// Role 角色数据模型
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	IsSystem    bool     `json:"is_system"`
+	Permissions []string `json:"permissions"`
+}
+
+// Permission 权限数据模型
+type Permission struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// ListPermissions 查询所有权限
+func ListPermissions() ([]Permission, error) {
+	rows, err := DB.Query("SELECT code, description FROM permissions ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []Permission{}
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.Code, &p.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions, rows.Err()
+}
+
+// ListRoles 查询所有角色及其权限
+func ListRoles() ([]*Role, error) {
+	rows, err := DB.Query("SELECT id, name, description, is_system FROM roles ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*Role{}
+	byID := make(map[int]*Role)
+	for rows.Next() {
+		role := &Role{Permissions: []string{}}
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+		byID[role.ID] = role
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	permRows, err := DB.Query("SELECT role_id, permission FROM role_permissions ORDER BY permission")
+	if err != nil {
+		return nil, err
+	}
+	defer permRows.Close()
+	for permRows.Next() {
+		var roleID int
+		var code string
+		if err := permRows.Scan(&roleID, &code); err != nil {
+			return nil, err
+		}
+		if role, ok := byID[roleID]; ok {
+			role.Permissions = append(role.Permissions, code)
+		}
+	}
+	return roles, permRows.Err()
+}
+
+// CreateRole 创建角色并设置其权限
+func CreateRole(role *Role) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var taken bool
+	if err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM roles WHERE name = ?)", role.Name).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		err = ErrRoleNameTaken
+		return err
+	}
+
+	result, err := tx.Exec("INSERT INTO roles (name, description) VALUES (?, ?)", role.Name, role.Description)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	role.ID = int(id)
+
+	if err = replaceRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// UpdateRole 修改角色说明并替换其权限，管理员角色拥有全部权限无需配置
+func UpdateRole(role *Role) error {
+	if role.ID == RoleAdmin {
+		return ErrAdminRole
+	}
+
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var exists bool
+	if err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM roles WHERE id = ?)", role.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		err = ErrRoleNotFound
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE roles SET description = ? WHERE id = ?", role.Description, role.ID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", role.ID); err != nil {
+		return err
+	}
+	if err = replaceRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// DeleteRole 删除角色，已授予该角色的用户随之失去对应权限
+func DeleteRole(id int) error {
+	var isSystem bool
+	err := DB.QueryRow("SELECT is_system FROM roles WHERE id = ?", id).Scan(&isSystem)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if isSystem {
+		return ErrSystemRole
+	}
+
+	_, err = DB.Exec("DELETE FROM roles WHERE id = ?", id)
+	return err
+}
+
+// replaceRolePermissions 为角色写入权限，权限代码必须存在
+func replaceRolePermissions(tx *sql.Tx, roleID int, codes []string) error {
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM permissions WHERE code = ?)", code).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUnknownPermission
+		}
+		if _, err := tx.Exec("INSERT INTO role_permissions (role_id, permission) VALUES (?, ?)", roleID, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUserRoles 查询用户在基础角色之外额外拥有的角色（包括自动授予的系统角色）
+func GetUserRoles(userID int) ([]Role, error) {
+	rows, err := DB.Query(
+		"SELECT r.id, r.name, r.description, r.is_system FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = ? ORDER BY r.id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// SetUserRoles 替换用户手动授予的角色，基础角色（普通用户、管理员）通过 SetUserRole 修改，系统角色不能手动授予
+func SetUserRoles(userID int, roleIDs []int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var exists bool
+	if err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", userID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		err = ErrUserNotFound
+		return err
+	}
+
+	// 只替换手动授予的角色，保留自动授予的系统角色（如认证律师）
+	if _, err = tx.Exec(
+		"DELETE ur FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = ? AND r.is_system = 0", userID,
+	); err != nil {
+		return err
+	}
+	seen := make(map[int]bool)
+	for _, roleID := range roleIDs {
+		if seen[roleID] {
+			continue
+		}
+		seen[roleID] = true
+
+		var isSystem bool
+		err = tx.QueryRow("SELECT is_system FROM roles WHERE id = ?", roleID).Scan(&isSystem)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrRoleNotFound
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if isSystem {
+			err = ErrSystemRole
+			return err
+		}
+		if _, err = tx.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", userID, roleID); err != nil {
+			return err
+		}
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// HasPermission 检查用户是否拥有指定权限：管理员拥有全部权限，
+// 其他用户的权限来自基础角色和额外授予的角色
+func HasPermission(user *User, code string) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+
+	var has bool
+	err := DB.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM role_permissions WHERE permission = ? AND "+
+			"(role_id = ? OR role_id IN (SELECT role_id FROM user_roles WHERE user_id = ?)))",
+		code, user.Role, user.ID,
+	).Scan(&has)
+	return has, err
+}
+
+// GetUserPermissions 查询用户拥有的全部权限
+func GetUserPermissions(user *User) ([]string, error) {
+	query := "SELECT DISTINCT permission FROM role_permissions WHERE role_id = ? OR role_id IN (SELECT role_id FROM user_roles WHERE user_id = ?) ORDER BY permission"
+	args := []interface{}{user.Role, user.ID}
+	if user.IsAdmin() {
+		query = "SELECT code FROM permissions ORDER BY code"
+		args = nil
+	}
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, code)
+	}
+	return permissions, rows.Err()
+}
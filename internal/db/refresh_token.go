@@ -0,0 +1,112 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// 刷新令牌错误
+var (
+	ErrRefreshTokenInvalid = errors.New("刷新令牌无效或已过期")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，该登录会话已失效")
+)
+
+// CreateRefreshToken 保存新签发的刷新令牌哈希
+func CreateRefreshToken(userID int, familyID, tokenHash string, expiresAt time.Time) error {
+	_, err := DB.Exec(
+		"INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES (?, ?, ?, ?)",
+		userID, familyID, tokenHash, expiresAt,
+	)
+	return err
+}
+
+// RotateRefreshToken 使用旧刷新令牌换取新令牌，返回令牌所属用户ID，now 为判断旧令牌是否过期的当前时间
+// 已使用或已吊销的令牌再次出现视为被盗用，整个令牌家族会被吊销
+func RotateRefreshToken(oldHash, newHash string, now, expiresAt time.Time) (int, error) {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定旧令牌
+	var id, userID int
+	var familyID string
+	var tokenExpiresAt time.Time
+	var usedAt, revokedAt sql.NullTime
+	err = tx.QueryRow(
+		"SELECT id, user_id, family_id, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = ? FOR UPDATE",
+		oldHash,
+	).Scan(&id, &userID, &familyID, &tokenExpiresAt, &usedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrRefreshTokenInvalid
+		return 0, err
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// 重复使用：吊销整个家族并提交
+	if usedAt.Valid || revokedAt.Valid {
+		if _, err = tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = ? AND revoked_at IS NULL", familyID); err != nil {
+			return 0, err
+		}
+		if err = tx.Commit(); err != nil {
+			return 0, err
+		}
+		return 0, ErrRefreshTokenReused
+	}
+	if now.After(tokenExpiresAt) {
+		err = ErrRefreshTokenInvalid
+		return 0, err
+	}
+
+	// 标记旧令牌已使用，并在同一家族中签发新令牌
+	if _, err = tx.Exec("UPDATE refresh_tokens SET used_at = NOW() WHERE id = ?", id); err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES (?, ?, ?, ?)",
+		userID, familyID, newHash, expiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// 提交事务
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// RevokeUserRefreshTokens 吊销用户的全部刷新令牌
+func RevokeUserRefreshTokens(userID int) error {
+	_, err := DB.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL", userID)
+	return err
+}
+
+// RevokeRefreshTokenFamily 吊销刷新令牌所在的整个令牌家族（退出当前登录会话）
+func RevokeRefreshTokenFamily(tokenHash string) error {
+	_, err := DB.Exec(
+		"UPDATE refresh_tokens t JOIN (SELECT family_id FROM refresh_tokens WHERE token_hash = ?) f ON t.family_id = f.family_id "+
+			"SET t.revoked_at = NOW() WHERE t.revoked_at IS NULL",
+		tokenHash,
+	)
+	return err
+}
+
+// DeleteExpiredRefreshTokens 清理已过期的刷新令牌
+func DeleteExpiredRefreshTokens() (int64, error) {
+	result, err := DB.Exec("DELETE FROM refresh_tokens WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/VanVodkaer/LawConnect-API/utils/region"
+)
+
+// 每批导入的区划数量
+const regionBatchSize = 500
+
+// ErrRegionNotFound 行政区划不存在
+var ErrRegionNotFound = errors.New("地区不存在")
+
+// Region 行政区划
+type Region struct {
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	ParentCode *string `json:"parent_code"`
+	Level      int     `json:"level"` // 1-省级，2-地级，3-县级
+}
+
+// regionSubtreeSQL 子查询：返回指定区划及其所有下级区划的代码，需要绑定 1 个区划代码参数
+const regionSubtreeSQL = "(WITH RECURSIVE region_tree AS (" +
+	"SELECT code FROM regions WHERE code = ? " +
+	"UNION ALL SELECT r.code FROM regions r JOIN region_tree t ON r.parent_code = t.code" +
+	") SELECT code FROM region_tree)"
+
+// regionAncestorsSQL 子查询：返回指定区划及其所有上级区划的代码和层级（自身为 0），需要绑定 1 个区划代码参数
+const regionAncestorsSQL = "WITH RECURSIVE region_path AS (" +
+	"SELECT code, name, parent_code, level, 0 AS depth FROM regions WHERE code = ? " +
+	"UNION ALL SELECT r.code, r.name, r.parent_code, r.level, p.depth + 1 FROM regions r JOIN region_path p ON r.code = p.parent_code" +
+	")"
+
+// ImportRegions 导入行政区划，已存在的区划更新名称、上级和级别，不删除文件中已不存在的区划
+func ImportRegions(regions []region.Region) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 上级区划排在前面，满足外键约束
+	ordered := make([]region.Region, 0, len(regions))
+	for level := region.LevelProvince; level <= region.LevelDistrict; level++ {
+		for _, r := range regions {
+			if r.Level == level {
+				ordered = append(ordered, r)
+			}
+		}
+	}
+
+	for start := 0; start < len(ordered); start += regionBatchSize {
+		end := start + regionBatchSize
+		if end > len(ordered) {
+			end = len(ordered)
+		}
+		batch := ordered[start:end]
+
+		query := "INSERT INTO regions (code, name, parent_code, level) VALUES "
+		args := make([]interface{}, 0, len(batch)*4)
+		for i, r := range batch {
+			if i > 0 {
+				query += ","
+			}
+			query += "(?, ?, ?, ?)"
+			var parent interface{}
+			if r.ParentCode != "" {
+				parent = r.ParentCode
+			}
+			args = append(args, r.Code, r.Name, parent, r.Level)
+		}
+		query += " ON DUPLICATE KEY UPDATE name = VALUES(name), parent_code = VALUES(parent_code), level = VALUES(level)"
+		if _, err = tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// RegionExists 检查行政区划是否存在
+func RegionExists(code string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM regions WHERE code = ?)", code).Scan(&exists)
+	return exists, err
+}
+
+// ListRegions 查询下级行政区划，parentCode 为空时返回全部省级区划
+func ListRegions(parentCode string) ([]Region, error) {
+	query := "SELECT code, name, parent_code, level FROM regions WHERE parent_code = ? ORDER BY code"
+	args := []interface{}{parentCode}
+	if parentCode == "" {
+		query = "SELECT code, name, parent_code, level FROM regions WHERE parent_code IS NULL ORDER BY code"
+		args = nil
+	}
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []Region{}
+	for rows.Next() {
+		r, err := scanRegion(rows)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, *r)
+	}
+	return regions, rows.Err()
+}
+
+// GetRegionPath 查询行政区划及其所有上级区划，按省、市、县的顺序排列
+func GetRegionPath(code string) ([]Region, error) {
+	rows, err := DB.Query(regionAncestorsSQL+" SELECT code, name, parent_code, level FROM region_path ORDER BY depth DESC", code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	path := []Region{}
+	for rows.Next() {
+		r, err := scanRegion(rows)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, ErrRegionNotFound
+	}
+	return path, nil
+}
+
+// scanRegion 读取 code、name、parent_code、level 列
+func scanRegion(row rowScanner) (*Region, error) {
+	var r Region
+	var parent sql.NullString
+	if err := row.Scan(&r.Code, &r.Name, &parent, &r.Level); err != nil {
+		return nil, err
+	}
+	if parent.Valid {
+		r.ParentCode = &parent.String
+	}
+	return &r, nil
+}
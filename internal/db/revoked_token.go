@@ -0,0 +1,27 @@
+package db
+
+import (
+	"time"
+)
+
+// RevokeToken 记录已吊销的访问令牌，重复吊销时忽略
+func RevokeToken(jti string, expiresAt time.Time) error {
+	_, err := DB.Exec("INSERT IGNORE INTO revoked_tokens (jti, expires_at) VALUES (?, ?)", jti, expiresAt)
+	return err
+}
+
+// IsTokenRevoked 检查访问令牌是否已被吊销
+func IsTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = ?)", jti).Scan(&revoked)
+	return revoked, err
+}
+
+// DeleteExpiredRevokedTokens 清理已过期的吊销记录（令牌本身已过期，无需继续记录）
+func DeleteExpiredRevokedTokens() (int64, error) {
+	result, err := DB.Exec("DELETE FROM revoked_tokens WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/utils/text"
+)
+
+// 搜索结果片段长度（字符数）
+const snippetLength = 120
+
+// 搜索结果类型
+const (
+	SearchTypeArticle = "article"
+	SearchTypeComment = "comment"
+)
+
+// SearchHit 一条搜索结果
+type SearchHit struct {
+	Type       string    `json:"type"`
+	ID         int       `json:"id"`
+	ArticleID  int       `json:"article_id"`
+	Title      string    `json:"title"`   // 文章标题（评论结果为所属文章的标题），命中部分已高亮
+	Snippet    string    `json:"snippet"` // 正文片段，命中部分已高亮
+	Score      float64   `json:"score"`
+	CreatedAt  time.Time `json:"created_at"`
+	CategoryID int       `json:"category_id"`
+}
+
+// SearchPage 一页搜索结果
+type SearchPage struct {
+	List     []SearchHit `json:"list"`
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+// SearchArticles 全文搜索可见文章，标题命中的权重为正文的两倍；categoryID 大于 0 时只搜索该分类子树
+func SearchArticles(keyword string, categoryID int, page Pagination) (*SearchPage, error) {
+	where := " FROM articles a WHERE a.is_visible = 1 AND MATCH(a.title, a.content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	args := []interface{}{keyword}
+	if categoryID > 0 {
+		where += " AND a.category_id IN " + categorySubtreeSQL(1)
+		args = append(args, categoryID)
+	}
+
+	query := "SELECT a.id, a.id, a.title, a.content, " +
+		"MATCH(a.title) AGAINST (? IN NATURAL LANGUAGE MODE) * 2 + MATCH(a.title, a.content) AGAINST (? IN NATURAL LANGUAGE MODE) AS score, " +
+		"a.created_at AS hit_at, a.category_id" + where
+	return search(SearchTypeArticle, keyword, where, args, query, append([]interface{}{keyword, keyword}, args...), page)
+}
+
+// SearchComments 全文搜索可见文章下的已通过审核评论；categoryID 大于 0 时只搜索该分类子树
+func SearchComments(keyword string, categoryID int, page Pagination) (*SearchPage, error) {
+	where := " FROM comments c JOIN articles a ON a.id = c.article_id" +
+		" WHERE c.is_visible = 1 AND a.is_visible = 1 AND MATCH(c.content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	args := []interface{}{keyword}
+	if categoryID > 0 {
+		where += " AND a.category_id IN " + categorySubtreeSQL(1)
+		args = append(args, categoryID)
+	}
+
+	query := "SELECT c.id, a.id, a.title, c.content, MATCH(c.content) AGAINST (? IN NATURAL LANGUAGE MODE) AS score, " +
+		"c.created_at AS hit_at, a.category_id" + where
+	return search(SearchTypeComment, keyword, where, args, query, append([]interface{}{keyword}, args...), page)
+}
+
+// search 执行统计与分页查询，并对标题和正文生成高亮片段
+func search(hitType, keyword, where string, countArgs []interface{}, query string, queryArgs []interface{}, page Pagination) (*SearchPage, error) {
+	page.Normalize()
+	result := &SearchPage{List: []SearchHit{}, Page: page.Page, PageSize: page.PageSize}
+
+	// 统计总数
+	if err := DB.QueryRow("SELECT COUNT(*)"+where, countArgs...).Scan(&result.Total); err != nil {
+		return nil, err
+	}
+
+	// 按相关度排序，相同得分时新内容优先
+	query += " ORDER BY score DESC, hit_at DESC LIMIT ? OFFSET ?"
+	rows, err := DB.Query(query, append(queryArgs, page.PageSize, page.Offset())...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := strings.Fields(keyword)
+	for rows.Next() {
+		hit := SearchHit{Type: hitType}
+		var content string
+		if err := rows.Scan(&hit.ID, &hit.ArticleID, &hit.Title, &content, &hit.Score, &hit.CreatedAt, &hit.CategoryID); err != nil {
+			return nil, err
+		}
+		hit.Title = text.HighlightAll(hit.Title, terms)
+		hit.Snippet = text.Highlight(content, terms, snippetLength)
+		result.List = append(result.List, hit)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/utils/filter"
+)
+
+// SensitiveWord 敏感词数据模型
+type SensitiveWord struct {
+	ID        int           `json:"id"`
+	Word      string        `json:"word"`
+	Action    filter.Action `json:"action"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ListSensitiveWords 获取全部敏感词
+func ListSensitiveWords() ([]SensitiveWord, error) {
+	rows, err := DB.Query("SELECT id, word, action, created_at FROM sensitive_words ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := []SensitiveWord{}
+	for rows.Next() {
+		var w SensitiveWord
+		if err := rows.Scan(&w.ID, &w.Word, &w.Action, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+	return words, rows.Err()
+}
+
+// GetFilterRules 读取敏感词表并转换为过滤规则
+func GetFilterRules() ([]filter.Rule, error) {
+	words, err := ListSensitiveWords()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]filter.Rule, 0, len(words))
+	for _, w := range words {
+		rules = append(rules, filter.Rule{Word: w.Word, Action: w.Action})
+	}
+	return rules, nil
+}
+
+// SaveSensitiveWord 添加敏感词，已存在时更新处理方式
+func SaveSensitiveWord(word string, action filter.Action) error {
+	_, err := DB.Exec(
+		"INSERT INTO sensitive_words (word, action) VALUES (?, ?) ON DUPLICATE KEY UPDATE action = VALUES(action)",
+		word, action,
+	)
+	return err
+}
+
+// DeleteSensitiveWord 删除敏感词
+func DeleteSensitiveWord(id int) error {
+	result, err := DB.Exec("DELETE FROM sensitive_words WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("敏感词不存在")
+	}
+	return nil
+}
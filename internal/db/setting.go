@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+// 系统设置项名称
+const (
+	SettingRequireAdminMFA = "security.require_admin_mfa" // 管理员必须启用两步验证
+)
+
+// GetSetting 获取设置项的值，未设置时返回默认值
+func GetSetting(name, def string) (string, error) {
+	var value string
+	err := DB.QueryRow("SELECT value FROM settings WHERE name = ?", name).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return def, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// SetSetting 保存设置项的值
+func SetSetting(name, value string) error {
+	_, err := DB.Exec(
+		"INSERT INTO settings (name, value) VALUES (?, ?) ON DUPLICATE KEY UPDATE value = VALUES(value)",
+		name, value,
+	)
+	return err
+}
+
+// GetBoolSetting 获取布尔类型的设置项，未设置或无法解析时返回默认值
+func GetBoolSetting(name string, def bool) (bool, error) {
+	value, err := GetSetting(name, strconv.FormatBool(def))
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, nil
+	}
+	return b, nil
+}
@@ -0,0 +1,458 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/utils/statute"
+)
+
+// 每批导入的条文数量
+const statuteArticleBatchSize = 500
+
+// 法规操作错误
+var (
+	ErrStatuteNotFound        = errors.New("法规不存在")
+	ErrStatuteVersionNotFound = errors.New("版本不存在")
+	ErrNoEffectiveVersion     = errors.New("该日期尚无施行的版本")
+	ErrStatuteArticleNotFound = errors.New("条文不存在")
+)
+
+// Statute 法律法规
+type Statute struct {
+	ID            int       `json:"id"`
+	Title         string    `json:"title"`
+	ShortTitle    string    `json:"short_title"`
+	Authority     string    `json:"authority"`
+	EffectiveDate *string   `json:"effective_date"` // 现行版本的施行日期，尚未施行时为空
+	VersionCount  int       `json:"version_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// StatuteVersion 法规的一个版本
+type StatuteVersion struct {
+	ID            int       `json:"id"`
+	EffectiveDate string    `json:"effective_date"`
+	Note          string    `json:"note"`
+	ArticleCount  int       `json:"article_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StatuteNode 法规的编、分编、章、节
+type StatuteNode struct {
+	ID       int               `json:"id"`
+	Kind     string            `json:"kind"`
+	Title    string            `json:"title"`
+	Nodes    []*StatuteNode    `json:"nodes,omitempty"`
+	Articles []*StatuteArticle `json:"articles,omitempty"`
+}
+
+// StatuteArticle 法规条文
+type StatuteArticle struct {
+	Number    int    `json:"number"`
+	SubNumber int    `json:"sub_number"`
+	Label     string `json:"label"` // 如 第一千一百六十五条
+	Ref       string `json:"ref"`   // 链接中的序号，如 1165、133-1
+	Content   string `json:"content"`
+}
+
+// StatuteArticleVersion 条文在某个版本中的内容
+type StatuteArticleVersion struct {
+	VersionID     int    `json:"version_id"`
+	EffectiveDate string `json:"effective_date"`
+	Note          string `json:"note"`
+	Content       string `json:"content"`
+}
+
+// StatuteImport 法规导入结果
+type StatuteImport struct {
+	StatuteID    int  `json:"statute_id"`
+	VersionID    int  `json:"version_id"`
+	Created      bool `json:"created"`  // 是否新收录的法规
+	Replaced     bool `json:"replaced"` // 是否替换了相同施行日期的已有版本
+	ArticleCount int  `json:"article_count"`
+}
+
+// newStatuteArticle 生成条文的序号文字
+func newStatuteArticle(number, subNumber int, content string) *StatuteArticle {
+	return &StatuteArticle{
+		Number:    number,
+		SubNumber: subNumber,
+		Label:     statute.ArticleLabel(number, subNumber),
+		Ref:       statute.ArticleRef(number, subNumber),
+		Content:   content,
+	}
+}
+
+// statuteColumns 法规查询的列（法规表别名为 s），与 scanStatute 的顺序一致
+const statuteColumns = "s.id, s.title, s.short_title, s.authority, " +
+	"(SELECT DATE_FORMAT(MAX(v.effective_date), '%Y-%m-%d') FROM statute_versions v WHERE v.statute_id = s.id AND v.effective_date <= CURDATE()), " +
+	"(SELECT COUNT(*) FROM statute_versions v WHERE v.statute_id = s.id), s.created_at, s.updated_at"
+
+// scanStatute 按 statuteColumns 的顺序读取法规
+func scanStatute(row rowScanner) (*Statute, error) {
+	var s Statute
+	var effectiveDate sql.NullString
+	if err := row.Scan(&s.ID, &s.Title, &s.ShortTitle, &s.Authority, &effectiveDate, &s.VersionCount, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if effectiveDate.Valid {
+		s.EffectiveDate = &effectiveDate.String
+	}
+	return &s, nil
+}
+
+// ImportStatute 导入法规的一个版本：按全称匹配已收录的法规，施行日期相同的版本整体替换；
+// 新收录的法规会补充识别已有文章和评论中对它的引用
+func ImportStatute(law *statute.Law, userID int) (*StatuteImport, error) {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result := &StatuteImport{}
+
+	// 锁定或创建法规
+	err = tx.QueryRow("SELECT id FROM statutes WHERE title = ? FOR UPDATE", law.Title).Scan(&result.StatuteID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		var res sql.Result
+		res, err = tx.Exec("INSERT INTO statutes (title, short_title, authority) VALUES (?, ?, ?)", law.Title, law.ShortTitle, law.Authority)
+		if err != nil {
+			return nil, err
+		}
+		var id int64
+		if id, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+		result.StatuteID, result.Created = int(id), true
+	case err != nil:
+		return nil, err
+	default:
+		// 简称和制定机关以最新导入的为准，未提供时保留原值
+		if _, err = tx.Exec(
+			"UPDATE statutes SET short_title = IF(? = '', short_title, ?), authority = IF(? = '', authority, ?) WHERE id = ?",
+			law.ShortTitle, law.ShortTitle, law.Authority, law.Authority, result.StatuteID,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	// 替换相同施行日期的版本（章节和条文由外键级联删除）
+	res, err := tx.Exec("DELETE FROM statute_versions WHERE statute_id = ? AND effective_date = ?", result.StatuteID, law.EffectiveDate)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	result.Replaced = affected > 0
+
+	res, err = tx.Exec(
+		"INSERT INTO statute_versions (statute_id, effective_date, note, imported_by) VALUES (?, ?, ?, ?)",
+		result.StatuteID, law.EffectiveDate, law.Note, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	versionID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	result.VersionID = int(versionID)
+
+	// 插入章节，收集条文及其所属章节
+	var rows []interface{}
+	order := 0
+	addArticles := func(nodeID interface{}, articles []*statute.Article) {
+		for _, a := range articles {
+			order++
+			rows = append(rows, result.VersionID, nodeID, a.Number, a.SubNumber, strings.TrimSpace(a.Content), order)
+		}
+	}
+	var addNodes func(parentID interface{}, nodes []*statute.Node) error
+	addNodes = func(parentID interface{}, nodes []*statute.Node) error {
+		for _, n := range nodes {
+			order++
+			res, err := tx.Exec(
+				"INSERT INTO statute_nodes (version_id, parent_id, kind, title, sort_order) VALUES (?, ?, ?, ?, ?)",
+				result.VersionID, parentID, n.Kind, strings.TrimSpace(n.Title), order,
+			)
+			if err != nil {
+				return err
+			}
+			nodeID, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			addArticles(nodeID, n.Articles)
+			if err := addNodes(nodeID, n.Nodes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	addArticles(nil, law.Articles)
+	if err = addNodes(nil, law.Nodes); err != nil {
+		return nil, err
+	}
+
+	// 分批插入条文
+	const columns = 6
+	result.ArticleCount = len(rows) / columns
+	for start := 0; start < len(rows); start += statuteArticleBatchSize * columns {
+		end := start + statuteArticleBatchSize*columns
+		if end > len(rows) {
+			end = len(rows)
+		}
+		query := "INSERT INTO statute_articles (version_id, node_id, number, sub_number, content, sort_order) VALUES " +
+			strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?),", (end-start)/columns), ",")
+		if _, err = tx.Exec(query, rows[start:end]...); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Created {
+		if err = backfillCitations(tx, law.Title, law.ShortTitle); err != nil {
+			return nil, err
+		}
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	invalidateStatuteIndex()
+	return result, nil
+}
+
+// ListStatutes 按名称（全称或简称模糊匹配）分页查询法规
+func ListStatutes(keyword string, page Pagination) ([]Statute, int, error) {
+	page.Normalize()
+
+	where := ""
+	var args []interface{}
+	if q := strings.TrimSpace(keyword); q != "" {
+		where = " WHERE s.title LIKE ? OR s.short_title LIKE ?"
+		pattern := "%" + escapeLike(q) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM statutes s"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT "+statuteColumns+" FROM statutes s"+where+" ORDER BY s.title LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []Statute{}
+	for rows.Next() {
+		s, err := scanStatute(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *s)
+	}
+	return list, total, rows.Err()
+}
+
+// GetStatute 获取法规信息
+func GetStatute(id int) (*Statute, error) {
+	s, err := scanStatute(DB.QueryRow("SELECT "+statuteColumns+" FROM statutes s WHERE s.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStatuteNotFound
+	}
+	return s, err
+}
+
+// DeleteStatute 删除法规及其全部版本和引用记录
+func DeleteStatute(id int) error {
+	result, err := DB.Exec("DELETE FROM statutes WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStatuteNotFound
+	}
+	invalidateStatuteIndex()
+	return nil
+}
+
+// statuteVersionColumns 版本查询的列（版本表别名为 v），与 scanStatuteVersion 的顺序一致
+const statuteVersionColumns = "v.id, DATE_FORMAT(v.effective_date, '%Y-%m-%d'), v.note, " +
+	"(SELECT COUNT(*) FROM statute_articles sa WHERE sa.version_id = v.id), v.created_at"
+
+// scanStatuteVersion 按 statuteVersionColumns 的顺序读取版本
+func scanStatuteVersion(row rowScanner) (*StatuteVersion, error) {
+	var v StatuteVersion
+	if err := row.Scan(&v.ID, &v.EffectiveDate, &v.Note, &v.ArticleCount, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListStatuteVersions 查询法规的全部版本，按施行日期倒序
+func ListStatuteVersions(statuteID int) ([]StatuteVersion, error) {
+	rows, err := DB.Query(
+		"SELECT "+statuteVersionColumns+" FROM statute_versions v WHERE v.statute_id = ? ORDER BY v.effective_date DESC",
+		statuteID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []StatuteVersion{}
+	for rows.Next() {
+		v, err := scanStatuteVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *v)
+	}
+	return versions, rows.Err()
+}
+
+// GetStatuteVersionAt 获取指定日期（2006-01-02）已施行的最新版本
+func GetStatuteVersionAt(statuteID int, date string) (*StatuteVersion, error) {
+	v, err := scanStatuteVersion(DB.QueryRow(
+		"SELECT "+statuteVersionColumns+" FROM statute_versions v WHERE v.statute_id = ? AND v.effective_date <= ? "+
+			"ORDER BY v.effective_date DESC LIMIT 1",
+		statuteID, date,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoEffectiveVersion
+	}
+	return v, err
+}
+
+// DeleteStatuteVersion 删除法规的一个版本
+func DeleteStatuteVersion(statuteID, versionID int) error {
+	result, err := DB.Exec("DELETE FROM statute_versions WHERE id = ? AND statute_id = ?", versionID, statuteID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStatuteVersionNotFound
+	}
+	invalidateStatuteIndex()
+	return nil
+}
+
+// GetStatuteContents 获取版本的章节树和全部条文，返回顶层章节和不属于任何章节的条文
+func GetStatuteContents(versionID int) ([]*StatuteNode, []*StatuteArticle, error) {
+	nodeRows, err := DB.Query("SELECT id, parent_id, kind, title FROM statute_nodes WHERE version_id = ? ORDER BY sort_order", versionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer nodeRows.Close()
+
+	nodes := []*StatuteNode{}
+	byID := make(map[int]*StatuteNode)
+	parents := make(map[int]int)
+	var order []int
+	for nodeRows.Next() {
+		var n StatuteNode
+		var parentID sql.NullInt64
+		if err := nodeRows.Scan(&n.ID, &parentID, &n.Kind, &n.Title); err != nil {
+			return nil, nil, err
+		}
+		byID[n.ID] = &n
+		order = append(order, n.ID)
+		if parentID.Valid {
+			parents[n.ID] = int(parentID.Int64)
+		}
+	}
+	if err := nodeRows.Err(); err != nil {
+		return nil, nil, err
+	}
+	for _, id := range order {
+		if parentID, ok := parents[id]; ok && byID[parentID] != nil {
+			byID[parentID].Nodes = append(byID[parentID].Nodes, byID[id])
+		} else {
+			nodes = append(nodes, byID[id])
+		}
+	}
+
+	articleRows, err := DB.Query(
+		"SELECT node_id, number, sub_number, content FROM statute_articles WHERE version_id = ? ORDER BY sort_order",
+		versionID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer articleRows.Close()
+
+	articles := []*StatuteArticle{}
+	for articleRows.Next() {
+		var nodeID sql.NullInt64
+		var number, subNumber int
+		var content string
+		if err := articleRows.Scan(&nodeID, &number, &subNumber, &content); err != nil {
+			return nil, nil, err
+		}
+		article := newStatuteArticle(number, subNumber, content)
+		if node := byID[int(nodeID.Int64)]; nodeID.Valid && node != nil {
+			node.Articles = append(node.Articles, article)
+		} else {
+			articles = append(articles, article)
+		}
+	}
+	return nodes, articles, articleRows.Err()
+}
+
+// GetStatuteArticleHistory 查询条文在各版本中的内容，按施行日期倒序，条文不存在时返回 ErrStatuteArticleNotFound
+func GetStatuteArticleHistory(statuteID, number, subNumber int) ([]StatuteArticleVersion, error) {
+	rows, err := DB.Query(
+		"SELECT v.id, DATE_FORMAT(v.effective_date, '%Y-%m-%d'), v.note, sa.content FROM statute_articles sa "+
+			"JOIN statute_versions v ON v.id = sa.version_id "+
+			"WHERE v.statute_id = ? AND sa.number = ? AND sa.sub_number = ? ORDER BY v.effective_date DESC",
+		statuteID, number, subNumber,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []StatuteArticleVersion{}
+	for rows.Next() {
+		var v StatuteArticleVersion
+		if err := rows.Scan(&v.VersionID, &v.EffectiveDate, &v.Note, &v.Content); err != nil {
+			return nil, err
+		}
+		history = append(history, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, ErrStatuteArticleNotFound
+	}
+	return history, nil
+}
@@ -2,7 +2,10 @@ package db
 
 import (
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -13,6 +16,16 @@ type User struct {
 	Email    string `json:"email"`
 	Password string `json:"-"` // 密码不通过JSON返回
 	Role     int    `json:"role"`
+
+	EmailVerified     bool       `json:"email_verified"`      // 邮箱是否已验证，未验证时只读
+	TOTPEnabled       bool       `json:"totp_enabled"`        // 是否启用两步验证
+	MustResetPassword bool       `json:"must_reset_password"` // 管理员要求重置密码，重置前不能登录
+	BannedAt          *time.Time `json:"banned_at"`           // 封禁时间，为空表示未封禁
+	BannedUntil       *time.Time `json:"banned_until"`        // 封禁截止时间，为空表示永久封禁
+	BanReason         string     `json:"ban_reason"`          // 封禁原因
+	Points            int        `json:"points"`              // 积分，用于问答悬赏
+	CreatedAt         time.Time  `json:"created_at"`          // 注册时间
+	TokensValidAfter  *time.Time `json:"-"`                   // 此时间及之前签发的令牌全部失效
 }
 
 // 角色常量
@@ -21,37 +34,57 @@ const (
 	RoleAdmin = 2 // 管理员
 )
 
-// GetUserByEmail 通过邮箱获取用户
-func GetUserByEmail(email string) (*User, error) {
+// 用户错误
+var (
+	ErrUserNotFound  = errors.New("用户不存在")
+	ErrUsernameTaken = errors.New("用户名已被使用")
+)
+
+// mysqlDuplicateEntry MySQL 唯一索引冲突的错误码
+const mysqlDuplicateEntry = 1062
+
+// userColumns 查询用户时使用的列，与 scanUser 的顺序一致
+const userColumns = "id, username, email, password, IFNULL(role, 1), email_verified, totp_enabled, must_reset_password, " +
+	"banned_at, banned_until, ban_reason, points, created_at, tokens_valid_after"
+
+// rowScanner 可以是 *sql.Row 或 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser 按 userColumns 的顺序读取用户
+func scanUser(row rowScanner) (*User, error) {
 	user := &User{}
-	query := "SELECT id, username, email, password, IFNULL(role, 1) FROM users WHERE email = ?"
-	err := DB.QueryRow(query, email).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.EmailVerified, &user.TOTPEnabled,
+		&user.MustResetPassword, &user.BannedAt, &user.BannedUntil, &user.BanReason, &user.Points, &user.CreatedAt, &user.TokensValidAfter)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, err
 	}
 	return user, nil
 }
 
-// GetUserByUsername 通过用户名获取用户
-func GetUserByUsername(username string) (*User, error) {
-	user := &User{}
-	query := "SELECT id, username, email, password, IFNULL(role, 1) FROM users WHERE username = ?"
-	err := DB.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
+// getUser 按条件查询单个用户
+func getUser(where string, arg interface{}) (*User, error) {
+	user, err := scanUser(DB.QueryRow("SELECT "+userColumns+" FROM users WHERE "+where, arg))
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, ErrUserNotFound
 	}
 	return user, nil
 }
 
+// GetUserByEmail 通过邮箱获取用户
+func GetUserByEmail(email string) (*User, error) {
+	return getUser("email = ?", email)
+}
+
+// GetUserByUsername 通过用户名获取用户
+func GetUserByUsername(username string) (*User, error) {
+	return getUser("username = ?", username)
+}
+
 // GetUserByID 通过ID获取用户
 func GetUserByID(id int) (*User, error) {
-	user := &User{}
-	query := "SELECT id, username, email, password, IFNULL(role, 1) FROM users WHERE id = ?"
-	err := DB.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
-	if err != nil {
-		return nil, errors.New("用户不存在")
-	}
-	return user, nil
+	return getUser("id = ?", id)
 }
 
 // CreateUser 创建用户
@@ -63,9 +96,14 @@ func CreateUser(user *User) error {
 	}
 
 	// 插入用户数据
-	query := "INSERT INTO users (username, email, password, role) VALUES (?, ?, ?, ?)"
-	result, err := DB.Exec(query, user.Username, user.Email, string(hashedPassword), user.Role)
+	query := "INSERT INTO users (username, email, password, role, email_verified) VALUES (?, ?, ?, ?, ?)"
+	result, err := DB.Exec(query, user.Username, user.Email, string(hashedPassword), user.Role, user.EmailVerified)
 	if err != nil {
+		// 并发注册同一用户名时由唯一索引拒绝
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry && strings.HasSuffix(mysqlErr.Message, "username'") {
+			return ErrUsernameTaken
+		}
 		return err
 	}
 
@@ -78,17 +116,76 @@ func CreateUser(user *User) error {
 	return nil
 }
 
+// UsernameExists 检查用户名是否已被使用（按表排序规则比较，不区分大小写）
+func UsernameExists(username string) (bool, error) {
+	return exists("SELECT COUNT(*) FROM users WHERE username = ?", username)
+}
+
 // CheckPassword 验证用户密码
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
 
+// dummyPasswordHash 账号不存在时用于比对的固定哈希，代价与真实密码哈希相同
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("lawconnect-dummy-password"), bcrypt.DefaultCost)
+
+// CheckDummyPassword 账号不存在时执行一次同样耗时的密码比对，避免通过响应时间探测账号是否存在，总是返回 false
+func CheckDummyPassword(password string) bool {
+	bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+	return false
+}
+
+// IsBanned 检查用户在 now 时是否处于封禁状态
+func (u *User) IsBanned(now time.Time) bool {
+	return u.BannedAt != nil && (u.BannedUntil == nil || u.BannedUntil.After(now))
+}
+
+// BanMessage 返回提示给被封禁用户的说明
+func (u *User) BanMessage() string {
+	message := "账号已被永久封禁"
+	if u.BannedUntil != nil {
+		message = "账号已被封禁至 " + u.BannedUntil.Format("2006-01-02 15:04")
+	}
+	if u.BanReason != "" {
+		message += "，原因：" + u.BanReason
+	}
+	return message
+}
+
 // IsAdmin 检查用户是否为管理员
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// SetEmailVerified 将用户邮箱标记为已验证
+func SetEmailVerified(userID int) error {
+	_, err := DB.Exec("UPDATE users SET email_verified = 1 WHERE id = ?", userID)
+	return err
+}
+
+// UpdatePassword 修改用户密码，并清除管理员要求的重置密码标记
+func UpdatePassword(userID int, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	_, err = DB.Exec("UPDATE users SET password = ?, must_reset_password = 0 WHERE id = ?", string(hashedPassword), userID)
+	return err
+}
+
+// InvalidateUserTokens 使用户在 now 及之前签发的全部令牌失效（退出所有设备）
+// now 取签发令牌使用的时钟，不使用数据库的 NOW()，避免时钟偏差和时区不一致
+func InvalidateUserTokens(userID int, now time.Time) error {
+	_, err := DB.Exec("UPDATE users SET tokens_valid_after = ? WHERE id = ?", tokensValidAfter(now), userID)
+	return err
+}
+
+// tokensValidAfter 将时间截断到毫秒，与令牌 iat 的精度和 tokens_valid_after 列的精度一致（避免数据库四舍五入）
+func tokensValidAfter(now time.Time) time.Time {
+	return now.Truncate(time.Millisecond)
+}
+
 // AdminExists 检查是否存在管理员用户
 func AdminExists() (bool, error) {
 	var count int
@@ -0,0 +1,305 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// 用户管理错误
+var (
+	ErrLastAdmin   = errors.New("至少需要保留一名管理员")
+	ErrInvalidRole = errors.New("无效的角色")
+)
+
+// 用户状态筛选
+const (
+	UserStatusActive = "active" // 未封禁
+	UserStatusBanned = "banned" // 封禁中
+)
+
+// UserFilter 用户查询条件，零值表示不限
+type UserFilter struct {
+	Query  string // 按用户名或邮箱模糊匹配
+	Role   int
+	Status string // active 或 banned
+}
+
+// ValidRole 检查角色是否有效
+func ValidRole(role int) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// ListUsers 分页查询用户，按注册时间倒序
+func ListUsers(filter UserFilter, page Pagination) ([]User, int, error) {
+	page.Normalize()
+
+	var conditions []string
+	var args []interface{}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		conditions = append(conditions, "(username LIKE ? OR email LIKE ?)")
+		args = append(args, pattern, pattern)
+	}
+	if filter.Role > 0 {
+		conditions = append(conditions, "role = ?")
+		args = append(args, filter.Role)
+	}
+	switch filter.Status {
+	case UserStatusBanned:
+		conditions = append(conditions, "banned_at IS NOT NULL AND (banned_until IS NULL OR banned_until > NOW())")
+	case UserStatusActive:
+		conditions = append(conditions, "(banned_at IS NULL OR banned_until <= NOW())")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM users"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := DB.Query(
+		"SELECT "+userColumns+" FROM users"+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, page.PageSize, page.Offset())...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+	return users, total, rows.Err()
+}
+
+// SetUserRole 修改用户角色，不允许撤销最后一名管理员
+func SetUserRole(userID, role int) error {
+	if !ValidRole(role) {
+		return ErrInvalidRole
+	}
+
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定管理员行，避免并发撤销导致没有管理员
+	var current int
+	if err = tx.QueryRow("SELECT role FROM users WHERE id = ? FOR UPDATE", userID).Scan(&current); err != nil {
+		err = ErrUserNotFound
+		return err
+	}
+	if current == RoleAdmin && role != RoleAdmin {
+		if err = ensureOtherAdmin(tx, userID); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.Exec("UPDATE users SET role = ? WHERE id = ?", role, userID); err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// ensureOtherAdmin 检查除 userID 外是否还有管理员
+func ensureOtherAdmin(q queryer, userID int) error {
+	var others int
+	err := q.QueryRow("SELECT COUNT(*) FROM users WHERE role = ? AND id <> ? FOR UPDATE", RoleAdmin, userID).Scan(&others)
+	if err != nil {
+		return err
+	}
+	if others == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// BanUser 封禁用户，until 为空表示永久封禁；now 及之前签发的令牌立即失效
+func BanUser(userID int, until *time.Time, reason string, now time.Time) error {
+	return updateUserAndRevoke(userID,
+		"UPDATE users SET banned_at = ?, banned_until = ?, ban_reason = ?, tokens_valid_after = ? WHERE id = ?",
+		now, until, reason, tokensValidAfter(now), userID,
+	)
+}
+
+// UnbanUser 解除封禁
+func UnbanUser(userID int) error {
+	result, err := DB.Exec("UPDATE users SET banned_at = NULL, banned_until = NULL, ban_reason = '' WHERE id = ?", userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		if _, err := GetUserByID(userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequirePasswordReset 要求用户重置密码：重置前不能登录，now 及之前签发的令牌立即失效
+func RequirePasswordReset(userID int, now time.Time) error {
+	return updateUserAndRevoke(userID,
+		"UPDATE users SET must_reset_password = 1, tokens_valid_after = ? WHERE id = ?",
+		tokensValidAfter(now), userID,
+	)
+}
+
+// updateUserAndRevoke 在同一事务中更新用户并吊销其全部刷新令牌
+func updateUserAndRevoke(userID int, query string, args ...interface{}) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		err = ErrUserNotFound
+		return err
+	}
+	_, err = tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL", userID)
+	if err != nil {
+		return err
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// DeleteUser 删除用户及其文章、评论和点赞（外键级联），并重新计算受影响文章和评论的点赞数、评论数
+func DeleteUser(userID int) error {
+	// 开启事务
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var role int
+	if err = tx.QueryRow("SELECT role FROM users WHERE id = ? FOR UPDATE", userID).Scan(&role); err != nil {
+		err = ErrUserNotFound
+		return err
+	}
+	if role == RoleAdmin {
+		if err = ensureOtherAdmin(tx, userID); err != nil {
+			return err
+		}
+	}
+
+	// 记录级联删除会影响计数的其他文章和评论
+	articleIDs, err := queryIDs(tx,
+		"SELECT article_id FROM comments WHERE user_id = ? UNION SELECT article_id FROM article_likes WHERE user_id = ?",
+		userID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	commentIDs, err := queryIDs(tx, "SELECT comment_id FROM comment_likes WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM users WHERE id = ?", userID); err != nil {
+		return err
+	}
+
+	// 级联删除后重新计算计数，已被删除的行不受影响
+	if len(articleIDs) > 0 {
+		_, err = tx.Exec(
+			"UPDATE articles SET "+
+				"comment_count = (SELECT COUNT(*) FROM comments c WHERE c.article_id = articles.id AND c.is_visible = ?), "+
+				"likes = (SELECT COUNT(*) FROM article_likes l WHERE l.article_id = articles.id) "+
+				"WHERE id IN ("+placeholders(len(articleIDs))+")",
+			append([]interface{}{CommentVisible}, intArgs(articleIDs)...)...,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if len(commentIDs) > 0 {
+		_, err = tx.Exec(
+			"UPDATE comments SET likes = (SELECT COUNT(*) FROM comment_likes l WHERE l.comment_id = comments.id) "+
+				"WHERE id IN ("+placeholders(len(commentIDs))+")",
+			intArgs(commentIDs)...,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 提交事务
+	err = tx.Commit()
+	return err
+}
+
+// queryIDs 查询一列整数ID
+func queryIDs(q queryer, query string, args ...interface{}) ([]int, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// intArgs 将整数切片转换为查询参数
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// UserRoleRequest 修改用户角色的请求结构
+type UserRoleRequest struct {
+	Role int `json:"role" binding:"required"`
+}
+
+// BanUserRequest 封禁用户的请求结构，expires_at 为空表示永久封禁
+type BanUserRequest struct {
+	Reason    string     `json:"reason" binding:"required,max=255"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// GetUsers 按用户名或邮箱搜索并分页查询用户（管理员）
+func GetUsers(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filter := db.UserFilter{
+		Query:  c.Query("q"),
+		Status: c.Query("status"),
+	}
+	if v := c.Query("role"); v != "" {
+		role, err := strconv.Atoi(v)
+		if err != nil || !db.ValidRole(role) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的角色"})
+			return
+		}
+		filter.Role = role
+	}
+	if filter.Status != "" && filter.Status != db.UserStatusActive && filter.Status != db.UserStatusBanned {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "status 应为 active 或 banned"})
+		return
+	}
+
+	users, total, err := db.ListUsers(filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询用户失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":      users,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// GetUserDetail 获取用户的账号信息、资料和统计数据（管理员）
+func GetUserDetail(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+	profile, err := db.GetProfile(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询用户失败"})
+		return
+	}
+	stats, err := db.GetUserStats(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询用户失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"user":    user,
+			"profile": profile,
+			"stats":   stats,
+		},
+	})
+}
+
+// UpdateUserRole 修改用户角色（管理员）
+func UpdateUserRole(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var req UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	if err := db.SetUserRole(userID, req.Role); err != nil {
+		respondUserAdminError(c, err, "修改角色失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "修改成功"})
+}
+
+// BanUser 封禁或暂停用户（管理员），已登录的会话立即失效
+func BanUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var req BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(auth.Tokens.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "封禁截止时间必须晚于当前时间"})
+		return
+	}
+	if userID == c.GetInt("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "不能封禁自己"})
+		return
+	}
+
+	if err := db.BanUser(userID, req.ExpiresAt, req.Reason, auth.Tokens.Now()); err != nil {
+		respondUserAdminError(c, err, "封禁用户失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已封禁"})
+}
+
+// UnbanUser 解除封禁（管理员）
+func UnbanUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := db.UnbanUser(userID); err != nil {
+		respondUserAdminError(c, err, "解除封禁失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已解除封禁"})
+}
+
+// ForcePasswordReset 要求用户重置密码（管理员）：已登录的会话立即失效，并向用户发送重置密码邮件
+func ForcePasswordReset(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := db.RequirePasswordReset(userID, auth.Tokens.Now()); err != nil {
+		respondUserAdminError(c, err, "操作失败")
+		return
+	}
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		respondUserAdminError(c, err, "操作失败")
+		return
+	}
+	if err := sendEmailToken(user, auth.PurposeResetPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "发送重置密码邮件失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已要求用户重置密码，重置密码邮件已发送"})
+}
+
+// DeleteUser 删除用户及其发布的内容（管理员）
+func DeleteUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if userID == c.GetInt("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "不能删除自己"})
+		return
+	}
+
+	if err := db.DeleteUser(userID); err != nil {
+		respondUserAdminError(c, err, "删除用户失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// userIDParam 解析路径中的用户ID，无效时写入错误响应
+func userIDParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的用户ID"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// respondUserAdminError 将用户管理错误转换为响应
+func respondUserAdminError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, db.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case errors.Is(err, db.ErrLastAdmin), errors.Is(err, db.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message})
+	}
+}
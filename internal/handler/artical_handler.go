@@ -1,140 +1,378 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/filter"
+	"github.com/VanVodkaer/LawConnect-API/utils/region"
 	"github.com/gin-gonic/gin"
 )
 
-// ----------------------- 法学交流社区 -----------------------
+// ListSection 生成栏目文章列表处理程序，列出一组分类（含子分类）下按指定方式排序的文章
+// 查询参数：page、page_size 为偏移分页，cursor 为上一页返回的 next_cursor，region 为地区代码（含下级地区）
+func ListSection(categoryIDs []int, sort db.ArticleSort) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := bindCursorPagination(c)
+		if !ok {
+			return
+		}
 
-// GetCommunityLatest 获取法学交流社区【最新动态】（按发布时间倒序）
-func GetCommunityLatest(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(1, "created_at DESC")
+		regionCode := c.Query("region")
+		if regionCode != "" && !region.ValidCode(regionCode) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的地区代码"})
+			return
+		}
+
+		result, err := db.ListArticlesByCategories(categoryIDs, regionCode, sort, page)
+		if err != nil {
+			if errors.Is(err, db.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": result})
+	}
+}
+
+// bindPagination 从查询参数解析偏移分页参数，携带游标或超过最大偏移量时返回 400
+func bindPagination(c *gin.Context) (db.Pagination, bool) {
+	if c.Query("cursor") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "该列表不支持游标分页"})
+		return db.Pagination{}, false
+	}
+	return bindCursorPagination(c)
+}
+
+// bindCursorPagination 从查询参数解析分页参数，支持游标分页的列表使用，超过最大偏移量时返回 400
+func bindCursorPagination(c *gin.Context) (db.Pagination, bool) {
+	var page db.Pagination
+	var err error
+	if v := c.Query("page"); v != "" {
+		if page.Page, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的页码"})
+			return page, false
+		}
+	}
+	if v := c.Query("page_size"); v != "" {
+		if page.PageSize, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的每页条数"})
+			return page, false
+		}
+	}
+	page.Cursor = c.Query("cursor")
+	if err := page.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return page, false
+	}
+	page.Normalize()
+	return page, true
+}
+
+// GetArticleDetail 获取文章详情及评论
+func GetArticleDetail(c *gin.Context) {
+	// 获取文章ID参数
+	id := c.Param("id")
+	articleID, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的文章ID"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
-}
 
-// GetCommunityHottest 获取法学交流社区【最热帖子】（按点赞数量倒序）
-func GetCommunityHottest(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(1, "likes DESC")
+	// 查询文章详情
+	article, err := db.GetArticleByID(articleID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
-}
 
-// GetCommunityHotQA 获取法学交流社区【热门问答】（按评论数量倒序）
-func GetCommunityHotQA(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(1, "comment_count DESC")
+	// 查询文章评论树
+	comments, err := db.GetCommentTree(articleID, commentDepth(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询评论失败"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
-}
 
-// ----------------------- 政策推送专区 -----------------------
+	// 将正文和评论中的法条引用转换为链接
+	if !linkCitations(c, article, comments) {
+		return
+	}
+
+	// 返回文章详情和评论
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"article":  article,
+			"comments": comments,
+		},
+	})
+}
 
-// GetPolicyLatest 获取政策推送专区【最新政策】（parent category_id=2）
-func GetPolicyLatest(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(2, "created_at DESC")
+// GetCommentReplies 获取评论下的回复树
+// 查询参数：depth 为返回的回复层数
+func GetCommentReplies(c *gin.Context) {
+	commentID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的评论ID"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
-}
 
-// GetPolicyLocal 获取政策推送专区【地方政策】（category_id=4）
-func GetPolicyLocal(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(4, "created_at DESC")
+	replies, err := db.GetCommentReplies(commentID, commentDepth(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "评论不存在或未通过审核"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询回复失败"})
+		return
+	}
+	if !linkCitations(c, nil, replies) {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": replies})
 }
 
-// GetPolicyInterpretation 获取政策推送专区【政策解读】（category_id=5）
-func GetPolicyInterpretation(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(5, "created_at DESC")
+// commentDepth 从查询参数 depth 解析评论树层数，非法值使用默认层数
+func commentDepth(c *gin.Context) int {
+	depth, err := strconv.Atoi(c.Query("depth"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
-		return
+		return db.DefaultCommentDepth
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
+	return depth
 }
 
-// ----------------------- 线下实践平台 -----------------------
+// ----------------------- 文章发布与管理 -----------------------
 
-// GetOfflineCooperation 获取线下实践平台【线下联动】（category_id=6）
-func GetOfflineCooperation(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(6, "created_at DESC")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+// ArticleRequest 创建或编辑文章的请求结构
+type ArticleRequest struct {
+	Title      string `json:"title" binding:"required,max=255"`
+	Content    string `json:"content" binding:"required"`
+	CategoryID int    `json:"category_id" binding:"required"`
+	CoverURL   string `json:"cover_url" binding:"omitempty,url,max=512"`
+	RegionCode string `json:"region_code"` // 适用地区，为空表示不限地区
+}
+
+// CreateArticle 发布文章
+func CreateArticle(c *gin.Context) {
+	// 获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要登录后才能发布文章"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
-}
 
-// GetOfflineOnline 获取线下实践平台【线上活动】（category_id=7）
-func GetOfflineOnline(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(7, "created_at DESC")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+	// 解析请求体
+	var req ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
-}
 
-// GetOfflineRegistration 获取线下实践平台【报名中心】（category_id=8）
-func GetOfflineRegistration(c *gin.Context) {
-	articles, err := db.GetArticlesByCategoryAndOrder(8, "created_at DESC")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+	// 验证分类和地区是否存在
+	if !checkCategory(c, req.CategoryID) || !checkRegion(c, req.RegionCode) {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": articles})
+
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Title, &req.Content)
+	if !ok {
+		return
+	}
+
+	article := &db.Article{
+		Title:      req.Title,
+		Content:    req.Content,
+		CoverURL:   req.CoverURL,
+		CategoryID: req.CategoryID,
+		RegionCode: req.RegionCode,
+		UserID:     userID.(int),
+		IsVisible:  articleStatus(checked),
+	}
+	if err := db.CreateArticle(article); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "发布文章失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": articleSubmittedMessage(article.IsVisible, "发布成功"),
+		"data": gin.H{
+			"article_id": article.ID,
+			"is_visible": article.IsVisible,
+		},
+	})
 }
 
-// GetArticleDetail 获取文章详情及评论
-func GetArticleDetail(c *gin.Context) {
-	// 获取文章ID参数
-	id := c.Param("id")
-	articleID, err := strconv.Atoi(id)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的文章ID"})
+// UpdateArticle 编辑文章（仅作者或管理员）
+func UpdateArticle(c *gin.Context) {
+	article, ok := loadOwnedArticle(c)
+	if !ok {
 		return
 	}
 
-	// 查询文章详情
-	article, err := db.GetArticleByID(articleID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
+	// 解析请求体
+	var req ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
 		return
 	}
 
-	// 查询文章评论
-	comments, err := db.GetCommentsByArticleID(articleID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询评论失败"})
+	// 验证分类和地区是否存在
+	if !checkCategory(c, req.CategoryID) || !checkRegion(c, req.RegionCode) {
+		return
+	}
+
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Title, &req.Content)
+	if !ok {
+		return
+	}
+
+	article.Title = req.Title
+	article.Content = req.Content
+	article.CoverURL = req.CoverURL
+	article.CategoryID = req.CategoryID
+	article.RegionCode = req.RegionCode
+	// 已隐藏（待审核或被下架）的文章编辑后仍需审核通过才能重新显示
+	if article.IsVisible != db.ArticleHidden {
+		article.IsVisible = articleStatus(checked)
+	}
+	if err := db.UpdateArticle(article); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "编辑文章失败: " + err.Error()})
 		return
 	}
 
-	// 返回文章详情和评论
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "成功",
+		"message": articleSubmittedMessage(article.IsVisible, "编辑成功"),
 		"data": gin.H{
-			"article":  article,
-			"comments": comments,
+			"article_id": article.ID,
+			"is_visible": article.IsVisible,
 		},
 	})
 }
+
+// DeleteArticle 删除文章（仅作者或管理员）
+func DeleteArticle(c *gin.Context) {
+	article, ok := loadOwnedArticle(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteArticle(article.ID, c.GetInt("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除文章失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data": gin.H{
+			"article_id": article.ID,
+		},
+	})
+}
+
+// articleStatus 根据敏感词检查结果决定文章可见状态，需人工审核的文章先隐藏
+func articleStatus(checked filter.Result) int {
+	if checked.NeedsReview() {
+		return db.ArticleHidden
+	}
+	return db.ArticleVisible
+}
+
+// articleSubmittedMessage 根据文章状态返回提示信息
+func articleSubmittedMessage(status int, visible string) string {
+	if status == db.ArticleHidden {
+		return "已提交，等待审核"
+	}
+	return visible
+}
+
+// checkCategory 验证分类是否存在以及当前用户能否在该分类下发布文章，不满足时直接写入错误响应
+func checkCategory(c *gin.Context, categoryID int) bool {
+	exists, err := db.CategoryExists(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询分类失败"})
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "分类不存在"})
+		return false
+	}
+
+	// 分类要求特定权限时（如政策专区需要 policy.edit），检查当前用户是否拥有
+	permission, err := db.GetCategoryPublishPermission(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询分类失败"})
+		return false
+	}
+	if permission == "" {
+		return true
+	}
+	has, err := userHasPermission(c, permission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询权限失败"})
+		return false
+	}
+	if !has {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "没有在该分类下发布文章的权限"})
+		return false
+	}
+	return true
+}
+
+// userHasPermission 检查当前登录用户是否拥有指定权限
+func userHasPermission(c *gin.Context, code string) (bool, error) {
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		return false, nil
+	}
+	return db.HasPermission(user, code)
+}
+
+// loadOwnedArticle 根据路径参数加载文章，并校验当前用户是作者或管理员
+func loadOwnedArticle(c *gin.Context) (*db.Article, bool) {
+	// 获取文章ID
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的文章ID"})
+		return nil, false
+	}
+
+	// 从上下文中获取用户
+	value, exists := c.Get("user")
+	user, ok := value.(*db.User)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return nil, false
+	}
+
+	// 验证文章是否存在，待审核或已下架的文章作者仍可编辑和删除
+	article, err := db.GetArticleIncludingHidden(articleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "文章不存在或已被删除"})
+		return nil, false
+	}
+
+	// 只有作者本人或拥有文章管理权限的用户可以操作
+	if article.UserID != user.ID {
+		has, err := db.HasPermission(user, db.PermArticleModerate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询权限失败"})
+			return nil, false
+		}
+		if !has {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "只有作者或管理员可以操作该文章"})
+			return nil, false
+		}
+	}
+	return article, true
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryRequest 创建分类的请求结构
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	ParentID *int   `json:"parent_id"`
+}
+
+// RenameCategoryRequest 重命名分类的请求结构
+type RenameCategoryRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// MoveCategoryRequest 移动分类的请求结构，parent_id 为 null 时移动为根分类
+type MoveCategoryRequest struct {
+	ParentID *int `json:"parent_id"`
+}
+
+// CategoryPermissionRequest 设置分类发布权限的请求结构，permission 为空时继承父分类
+type CategoryPermissionRequest struct {
+	Permission string `json:"permission" binding:"max=50"`
+}
+
+// GetCategoryTree 获取分类树
+func GetCategoryTree(c *gin.Context) {
+	tree, err := db.GetCategoryTree()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询分类失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": tree})
+}
+
+// CreateCategory 创建分类（管理员）
+func CreateCategory(c *gin.Context) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	category := &db.Category{Name: req.Name, ParentID: req.ParentID}
+	if err := db.CreateCategory(category); err != nil {
+		respondCategoryError(c, "创建分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "创建成功", "data": category})
+}
+
+// RenameCategory 重命名分类（管理员）
+func RenameCategory(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req RenameCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := db.RenameCategory(id, req.Name); err != nil {
+		respondCategoryError(c, "重命名分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "重命名成功", "data": gin.H{"category_id": id}})
+}
+
+// MoveCategory 移动分类到新的父分类下（管理员）
+func MoveCategory(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := db.MoveCategory(id, req.ParentID); err != nil {
+		respondCategoryError(c, "移动分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "移动成功", "data": gin.H{"category_id": id}})
+}
+
+// DeleteCategory 删除分类及其子分类（管理员）
+func DeleteCategory(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteCategory(id); err != nil {
+		respondCategoryError(c, "删除分类失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功", "data": gin.H{"category_id": id}})
+}
+
+// SetCategoryPermission 设置在分类及其子分类下发布文章所需的权限（管理员）
+func SetCategoryPermission(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req CategoryPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := db.SetCategoryPublishPermission(id, req.Permission); err != nil {
+		respondCategoryError(c, "设置发布权限失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "设置成功", "data": gin.H{"category_id": id}})
+}
+
+// categoryIDParam 解析路径中的分类ID
+func categoryIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的分类ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondCategoryError 将分类操作错误转换为响应
+func respondCategoryError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case errors.Is(err, db.ErrCategoryCycle), errors.Is(err, db.ErrCategoryNotEmpty), errors.Is(err, db.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message + ": " + err.Error()})
+	}
+}
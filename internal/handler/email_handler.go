@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/config"
+	"github.com/VanVodkaer/LawConnect-API/utils/mail"
+	"github.com/gin-gonic/gin"
+)
+
+// errEmailTokenInvalid 邮件链接无效、已使用或已过期
+var errEmailTokenInvalid = errors.New("链接无效或已失效，请重新获取")
+
+// EmailTokenRequest 提交邮件令牌的请求结构
+type EmailTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResetPasswordRequest 重置密码请求结构
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// sendEmailToken 签发并记录邮件令牌，然后在后台发送包含链接的邮件
+func sendEmailToken(user *db.User, purpose string) error {
+	token, err := auth.Tokens.IssueEmailToken(user, purpose)
+	if err != nil {
+		return err
+	}
+	if err := db.CreateEmailToken(token.ID, user.ID, purpose, token.ExpiresAt); err != nil {
+		return err
+	}
+
+	baseURL := strings.TrimRight(config.GlobalConfig.Mail.BaseURL, "/")
+	var msg mail.Message
+	switch purpose {
+	case auth.PurposeVerifyEmail:
+		link := baseURL + "/verify-email?token=" + url.QueryEscape(token.Token)
+		msg = mail.Message{
+			To:      user.Email,
+			Subject: "LawConnect 邮箱验证",
+			Body: fmt.Sprintf("%s，您好：\n\n请点击以下链接验证您的邮箱，链接在 %s 前有效：\n%s\n\n如果这不是您本人的操作，请忽略本邮件。",
+				user.Username, token.ExpiresAt.Format("2006-01-02 15:04"), link),
+		}
+	case auth.PurposeResetPassword:
+		link := baseURL + "/reset-password?token=" + url.QueryEscape(token.Token)
+		msg = mail.Message{
+			To:      user.Email,
+			Subject: "LawConnect 重置密码",
+			Body: fmt.Sprintf("%s，您好：\n\n我们收到了重置密码的请求，请点击以下链接设置新密码，链接在 %s 前有效且只能使用一次：\n%s\n\n如果这不是您本人的操作，请忽略本邮件，您的密码不会被修改。",
+				user.Username, token.ExpiresAt.Format("2006-01-02 15:04"), link),
+		}
+	}
+	mail.SendAsync(msg)
+	return nil
+}
+
+// useEmailToken 校验邮件令牌的签名、有效期和绑定的邮箱，并将其标记为已使用
+func useEmailToken(tokenString, purpose string) (*db.User, error) {
+	claims, err := auth.Tokens.ParseEmailToken(tokenString, purpose)
+	if err != nil {
+		return nil, errEmailTokenInvalid
+	}
+	user, err := db.GetUserByID(claims.UserID)
+	if err != nil || !strings.EqualFold(user.Email, claims.Email) {
+		return nil, errEmailTokenInvalid
+	}
+	used, err := db.UseEmailToken(claims.ID, user.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errEmailTokenInvalid
+	}
+	return user, nil
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func VerifyEmail(c *gin.Context) {
+	var req EmailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	user, err := useEmailToken(req.Token, auth.PurposeVerifyEmail)
+	if err != nil {
+		if errors.Is(err, errEmailTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "验证邮箱失败"})
+		return
+	}
+	if err := db.SetEmailVerified(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "验证邮箱失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "邮箱验证成功"})
+}
+
+// ResendVerification 重新发送邮箱验证邮件，之前发送的链接随之失效
+func ResendVerification(c *gin.Context) {
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return
+	}
+	if user.EmailVerified {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "邮箱已验证"})
+		return
+	}
+
+	if err := sendEmailToken(user, auth.PurposeVerifyEmail); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "发送验证邮件失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "验证邮件已发送"})
+}
+
+// ForgotPassword 发送重置密码邮件
+// 无论邮箱是否注册都返回相同的结果，避免据此探测账号是否存在
+func ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	if user, err := db.GetUserByEmail(req.Email); err == nil {
+		if err := sendEmailToken(user, auth.PurposeResetPassword); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "发送重置密码邮件失败"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "如果该邮箱已注册，重置密码邮件已发送"})
+}
+
+// ResetPassword 使用邮件中的令牌设置新密码，并使该用户所有已登录的会话失效
+func ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	user, err := useEmailToken(req.Token, auth.PurposeResetPassword)
+	if err != nil {
+		if errors.Is(err, errEmailTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+
+	if err := db.UpdatePassword(user.ID, req.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+	// 能收到重置邮件即证明邮箱属于本人
+	if err := db.SetEmailVerified(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+	// 退出所有设备并解除登录锁定
+	if err := db.InvalidateUserTokens(user.ID, auth.Tokens.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+	if err := db.RevokeUserRefreshTokens(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+	if err := auth.Guard.Succeed(auth.LoginAccountKey(user, ""), ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "密码已重置，请使用新密码登录"})
+}
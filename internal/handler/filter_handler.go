@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/filter"
+	"github.com/gin-gonic/gin"
+)
+
+// SensitiveWordRequest 添加敏感词的请求结构
+type SensitiveWordRequest struct {
+	Word   string        `json:"word" binding:"required,max=100"`
+	Action filter.Action `json:"action" binding:"required"`
+}
+
+// checkContent 对用户提交的内容执行敏感词过滤
+// 命中 block 规则时直接写入错误响应并返回 false，否则返回打码后的内容和合并后的检查结果
+func checkContent(c *gin.Context, texts ...*string) (filter.Result, bool) {
+	results := make([]filter.Result, 0, len(texts))
+	for _, text := range texts {
+		result := filter.Check(*text)
+		*text = result.Text
+		results = append(results, result)
+	}
+
+	merged := filter.Merge(results...)
+	if merged.Blocked() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "内容包含违禁词，无法提交"})
+		return merged, false
+	}
+	return merged, true
+}
+
+// GetSensitiveWords 获取敏感词列表（管理员）
+func GetSensitiveWords(c *gin.Context) {
+	words, err := db.ListSensitiveWords()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询敏感词失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": words})
+}
+
+// SaveSensitiveWord 添加或更新敏感词（管理员），保存后立即生效
+func SaveSensitiveWord(c *gin.Context) {
+	var req SensitiveWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !req.Action.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误，action 应为 block、mask 或 review"})
+		return
+	}
+
+	if err := db.SaveSensitiveWord(req.Word, req.Action); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存敏感词失败: " + err.Error()})
+		return
+	}
+	reloadSensitiveWords(c, "保存成功")
+}
+
+// DeleteSensitiveWord 删除敏感词（管理员），删除后立即生效
+func DeleteSensitiveWord(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的敏感词ID"})
+		return
+	}
+
+	if err := db.DeleteSensitiveWord(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+	reloadSensitiveWords(c, "删除成功")
+}
+
+// ReloadSensitiveWords 从数据库重新加载敏感词库（管理员）
+func ReloadSensitiveWords(c *gin.Context) {
+	reloadSensitiveWords(c, "重新加载成功")
+}
+
+// reloadSensitiveWords 重新加载敏感词库并返回响应
+func reloadSensitiveWords(c *gin.Context, message string) {
+	rules, err := db.GetFilterRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "加载敏感词库失败: " + err.Error()})
+		return
+	}
+	filter.Reload(rules)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data": gin.H{
+			"count": len(rules),
+		},
+	})
+}
+
+// SetArticleVisibilityRequest 设置文章可见状态的请求结构
+type SetArticleVisibilityRequest struct {
+	Visible bool `json:"visible"`
+}
+
+// SetArticleVisibility 审核通过或下架文章（管理员）
+func SetArticleVisibility(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的文章ID"})
+		return
+	}
+
+	var req SetArticleVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := db.SetArticleVisibility(articleID, req.Visible); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": gin.H{"article_id": articleID, "visible": req.Visible}})
+}
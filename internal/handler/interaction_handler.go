@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -65,8 +66,27 @@ func AddComment(c *gin.Context) {
 		return
 	}
 
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Content)
+	if !ok {
+		return
+	}
+
+	// 根据审核模式决定评论状态，命中需审核的敏感词时转入人工审核
+	status, err := commentStatus(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "添加评论失败: " + err.Error(),
+		})
+		return
+	}
+	if checked.NeedsReview() {
+		status = db.CommentPending
+	}
+
 	// 添加评论
-	commentID, err := db.AddComment(articleID, req.Content, userID.(int))
+	commentID, err := db.AddComment(articleID, req.Content, userID.(int), status)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -78,10 +98,95 @@ func AddComment(c *gin.Context) {
 	// 返回成功响应
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "评论成功",
+		"message": commentSubmittedMessage(status, "评论成功"),
 		"data": gin.H{
 			"comment_id": commentID,
 			"article_id": article.ID,
+			"status":     status,
+		},
+	})
+}
+
+// AddReply 回复评论处理程序
+func AddReply(c *gin.Context) {
+	// 获取父评论ID
+	parentIDStr := c.Param("id")
+	parentID, err := strconv.Atoi(parentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的评论ID",
+		})
+		return
+	}
+
+	// 获取用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "需要登录后才能回复",
+		})
+		return
+	}
+
+	// 解析请求体
+	var req AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Content)
+	if !ok {
+		return
+	}
+
+	// 根据审核模式决定回复状态，命中需审核的敏感词时转入人工审核
+	status, err := commentStatus(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "回复失败: " + err.Error(),
+		})
+		return
+	}
+	if checked.NeedsReview() {
+		status = db.CommentPending
+	}
+
+	// 添加回复
+	commentID, articleID, err := db.AddReply(parentID, req.Content, userID.(int), status)
+	if err != nil {
+		// 如果父评论不存在，返回特定响应
+		if err.Error() == "评论不存在或未通过审核" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "回复失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": commentSubmittedMessage(status, "回复成功"),
+		"data": gin.H{
+			"comment_id": commentID,
+			"parent_id":  parentID,
+			"article_id": articleID,
+			"status":     status,
 		},
 	})
 }
@@ -381,3 +486,197 @@ func GetCommentLikeStatus(c *gin.Context) {
 		},
 	})
 }
+
+// UpdateComment 编辑自己的评论
+func UpdateComment(c *gin.Context) {
+	// 获取评论ID
+	commentIDStr := c.Param("id")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的评论ID",
+		})
+		return
+	}
+
+	// 解析请求体
+	var req AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 验证评论是否存在
+	comment, err := db.GetCommentByID(commentID)
+	if err != nil {
+		respondCommentError(c, "编辑评论失败", err)
+		return
+	}
+
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Content)
+	if !ok {
+		return
+	}
+
+	// 重新决定审核状态：被驳回的评论编辑后需要重新审核
+	status, err := commentStatus(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "编辑评论失败: " + err.Error(),
+		})
+		return
+	}
+	if checked.NeedsReview() || comment.IsVisible == db.CommentRejected {
+		status = db.CommentPending
+	}
+
+	// 编辑评论
+	if err := db.UpdateComment(commentID, c.GetInt("user_id"), req.Content, status); err != nil {
+		respondCommentError(c, "编辑评论失败", err)
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": commentSubmittedMessage(status, "编辑成功"),
+		"data": gin.H{
+			"comment_id": commentID,
+			"status":     status,
+		},
+	})
+}
+
+// DeleteComment 删除评论（作者或管理员）
+func DeleteComment(c *gin.Context) {
+	// 获取评论ID
+	commentIDStr := c.Param("id")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的评论ID",
+		})
+		return
+	}
+
+	// 从上下文中获取用户
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "需要认证",
+		})
+		return
+	}
+
+	// 拥有评论管理权限的用户可以删除他人的评论
+	moderator, err := db.HasPermission(user, db.PermCommentModerate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询权限失败",
+		})
+		return
+	}
+
+	// 删除评论
+	if err := db.DeleteComment(commentID, user.ID, moderator); err != nil {
+		respondCommentError(c, "删除评论失败", err)
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data": gin.H{
+			"comment_id": commentID,
+		},
+	})
+}
+
+// GetCommentHistory 获取评论的编辑历史（作者或管理员）
+func GetCommentHistory(c *gin.Context) {
+	// 获取评论ID
+	commentIDStr := c.Param("id")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的评论ID",
+		})
+		return
+	}
+
+	// 验证评论是否存在
+	comment, err := db.GetCommentByID(commentID)
+	if err != nil {
+		respondCommentError(c, "获取编辑历史失败", err)
+		return
+	}
+
+	// 只有作者或拥有评论管理权限的用户可以查看
+	if comment.UserID != c.GetInt("user_id") {
+		has, err := userHasPermission(c, db.PermCommentModerate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "查询权限失败",
+			})
+			return
+		}
+		if !has {
+			respondCommentError(c, "获取编辑历史失败", db.ErrCommentForbidden)
+			return
+		}
+	}
+
+	// 查询编辑历史
+	edits, err := db.GetCommentEdits(commentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取编辑历史失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 返回编辑历史
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"comment": comment,
+			"edits":   edits,
+		},
+	})
+}
+
+// respondCommentError 将评论操作错误转换为响应
+func respondCommentError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, db.ErrCommentForbidden):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": message + ": " + err.Error(),
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// LawyerDocumentRequest 证明材料
+type LawyerDocumentRequest struct {
+	Name string `json:"name" binding:"max=100"`
+	URL  string `json:"url" binding:"required,url,max=512"`
+}
+
+// LawyerVerificationRequest 提交律师认证申请的请求结构
+type LawyerVerificationRequest struct {
+	LicenseNumber string                  `json:"license_number" binding:"required,max=50"`
+	LawFirm       string                  `json:"law_firm" binding:"required,max=100"`
+	Jurisdiction  string                  `json:"jurisdiction" binding:"required,max=100"`
+	Documents     []LawyerDocumentRequest `json:"documents" binding:"required,min=1,max=10,dive"`
+}
+
+// ApproveLawyerRequest 通过律师认证的请求结构，expires_at 为空表示长期有效
+type ApproveLawyerRequest struct {
+	Note      string     `json:"note" binding:"max=255"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// RejectLawyerRequest 驳回律师认证的请求结构
+type RejectLawyerRequest struct {
+	Note string `json:"note" binding:"required,max=255"`
+}
+
+// GetMyLawyerVerification 获取当前用户最近一次提交的律师认证申请
+func GetMyLawyerVerification(c *gin.Context) {
+	v, err := db.GetLatestLawyerVerification(c.GetInt("user_id"))
+	if err != nil {
+		if errors.Is(err, db.ErrVerificationNotFound) {
+			c.JSON(http.StatusOK, gin.H{"code": 200, "message": "尚未提交认证申请", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询认证申请失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": v})
+}
+
+// ApplyLawyerVerification 提交律师认证申请
+func ApplyLawyerVerification(c *gin.Context) {
+	var req LawyerVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	v := &db.LawyerVerification{
+		UserID:        c.GetInt("user_id"),
+		LicenseNumber: strings.TrimSpace(req.LicenseNumber),
+		LawFirm:       strings.TrimSpace(req.LawFirm),
+		Jurisdiction:  strings.TrimSpace(req.Jurisdiction),
+	}
+	for _, doc := range req.Documents {
+		v.Documents = append(v.Documents, db.LawyerDocument{Name: doc.Name, URL: doc.URL})
+	}
+	if err := db.CreateLawyerVerification(v); err != nil {
+		respondLawyerError(c, "提交认证申请失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已提交，等待审核",
+		"data": gin.H{
+			"verification_id": v.ID,
+			"status":          v.Status,
+		},
+	})
+}
+
+// GetLawyerVerifications 分页查询律师认证申请（审核队列），默认只看待审核的申请
+func GetLawyerVerifications(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	status := c.DefaultQuery("status", db.LawyerPending)
+	if status == "all" {
+		status = ""
+	} else if !db.ValidLawyerStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "status 应为 pending、approved、rejected、expired 或 all"})
+		return
+	}
+
+	list, total, err := db.ListLawyerVerifications(status, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询认证申请失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":      list,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// GetLawyerVerificationDetail 获取律师认证申请详情和证明材料
+func GetLawyerVerificationDetail(c *gin.Context) {
+	id, ok := verificationIDParam(c)
+	if !ok {
+		return
+	}
+
+	v, err := db.GetLawyerVerification(id)
+	if err != nil {
+		respondLawyerError(c, "查询认证申请失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": v})
+}
+
+// ApproveLawyerVerification 通过律师认证申请
+func ApproveLawyerVerification(c *gin.Context) {
+	id, ok := verificationIDParam(c)
+	if !ok {
+		return
+	}
+	var req ApproveLawyerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(auth.Tokens.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "认证有效期必须晚于当前时间"})
+		return
+	}
+
+	if err := db.ApproveLawyerVerification(id, c.GetInt("user_id"), req.ExpiresAt, req.Note); err != nil {
+		respondLawyerError(c, "审核失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已通过", "data": gin.H{"verification_id": id}})
+}
+
+// RejectLawyerVerification 驳回律师认证申请
+func RejectLawyerVerification(c *gin.Context) {
+	id, ok := verificationIDParam(c)
+	if !ok {
+		return
+	}
+	var req RejectLawyerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请填写驳回原因"})
+		return
+	}
+
+	if err := db.RejectLawyerVerification(id, c.GetInt("user_id"), req.Note); err != nil {
+		respondLawyerError(c, "审核失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已驳回", "data": gin.H{"verification_id": id}})
+}
+
+// verificationIDParam 解析路径中的认证申请ID
+func verificationIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的申请ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondLawyerError 将律师认证错误转换为响应
+func respondLawyerError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrVerificationNotFound), errors.Is(err, db.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case errors.Is(err, db.ErrVerificationPending), errors.Is(err, db.ErrVerificationReviewed):
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	case errors.Is(err, db.ErrVerificationSelf):
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message})
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoginHistory 获取当前用户的登录记录
+func GetLoginHistory(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	respondLoginAttempts(c, db.LoginAttemptFilter{UserID: c.GetInt("user_id")}, page)
+}
+
+// GetLoginAttempts 按用户、账号或IP查询登录审计记录（管理员）
+func GetLoginAttempts(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filter := db.LoginAttemptFilter{
+		Account: c.Query("account"),
+		IP:      c.Query("ip"),
+	}
+	if v := c.Query("user_id"); v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的用户ID"})
+			return
+		}
+		filter.UserID = userID
+	}
+	respondLoginAttempts(c, filter, page)
+}
+
+// respondLoginAttempts 查询并返回登录审计记录
+func respondLoginAttempts(c *gin.Context, filter db.LoginAttemptFilter, page db.Pagination) {
+	attempts, total, err := db.ListLoginAttempts(filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询登录记录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":      attempts,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// UnlockLogin 解除账号或IP的登录锁定（管理员）
+func UnlockLogin(c *gin.Context) {
+	var req struct {
+		UserID int    `json:"user_id"`
+		IP     string `json:"ip"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || (req.UserID <= 0 && req.IP == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请提供用户ID或IP"})
+		return
+	}
+
+	if req.UserID > 0 {
+		user, err := db.GetUserByID(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+			return
+		}
+		if err := db.ResetLoginFailures(db.LockoutScopeAccount, auth.LoginAccountKey(user, "")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "解除锁定失败"})
+			return
+		}
+	}
+	if req.IP != "" {
+		if err := db.ResetLoginFailures(db.LockoutScopeIP, req.IP); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "解除锁定失败"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已解除锁定"})
+}
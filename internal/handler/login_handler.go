@@ -1,35 +1,47 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
 	"github.com/VanVodkaer/LawConnect-API/internal/db"
-	"github.com/VanVodkaer/LawConnect-API/utils/config"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-// LoginRequest 登录请求结构
+// LoginRequest 登录请求结构，account 可以是用户名或邮箱
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
+	Account  string `json:"account"`
+	Username string `json:"username"` // 兼容旧客户端，等同于 account
 	Password string `json:"password" binding:"required"`
 }
 
+// identifier 返回登录时填写的用户名或邮箱
+func (r LoginRequest) identifier() string {
+	if r.Account != "" {
+		return strings.TrimSpace(r.Account)
+	}
+	return strings.TrimSpace(r.Username)
+}
+
 // LoginResponse 登录响应结构
 type LoginResponse struct {
-	Token  string  `json:"token"`
-	User   db.User `json:"user"`
-	Expire int64   `json:"expire"`
+	Token         string  `json:"token"`
+	RefreshToken  string  `json:"refresh_token"`
+	User          db.User `json:"user"`
+	Expire        int64   `json:"expire"`
+	RefreshExpire int64   `json:"refresh_expire"`
 }
 
-// Claims 自定义JWT声明结构
-type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     int    `json:"role"`
-	jwt.RegisteredClaims
+// RefreshRequest 刷新令牌请求结构
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // Login 处理用户登录请求
@@ -43,18 +55,48 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// 根据用户名查找用户
-	user, err := db.GetUserByUsername(loginReq.Username)
+	identifier := loginReq.identifier()
+	if identifier == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请输入用户名或邮箱",
+		})
+		return
+	}
+
+	// 根据邮箱或用户名查找用户
+	user := findLoginUser(identifier)
+	accountKey := auth.LoginAccountKey(user, identifier)
+	ip := c.ClientIP()
+
+	// 账号或IP已被锁定时不再校验密码
+	remaining, err := auth.Guard.Locked(accountKey, ip)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "用户名或密码不正确",
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "服务器内部错误",
 		})
 		return
 	}
+	if remaining > 0 {
+		recordLoginAttempt(c, user, identifier, db.LoginLocked)
+		respondLoginLocked(c, remaining)
+		return
+	}
 
-	// 验证密码
-	if !user.CheckPassword(loginReq.Password) {
+	// 验证密码，账号不存在时同样执行一次比对，使响应时间与密码错误时一致
+	if user == nil {
+		db.CheckDummyPassword(loginReq.Password)
+	}
+	if user == nil || !user.CheckPassword(loginReq.Password) {
+		reason := db.LoginBadPassword
+		if user == nil {
+			reason = db.LoginUnknownAccount
+		}
+		recordLoginAttempt(c, user, identifier, reason)
+		if !loginFailed(c, accountKey, ip) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
 			"message": "用户名或密码不正确",
@@ -62,22 +104,50 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// 生成JWT令牌
-	expireTime := time.Now().Add(time.Duration(config.GlobalConfig.JWT.Expire) * time.Hour)
-	claims := Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expireTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   fmt.Sprintf("%d", user.ID),
-		},
+	// 已封禁或需要重置密码的账号不能登录
+	if reason, message := accountBlocked(user); reason != "" {
+		recordLoginAttempt(c, user, identifier, reason)
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": message,
+		})
+		return
 	}
 
-	// 创建JWT令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.GlobalConfig.JWT.Secret))
+	// 已启用两步验证：先签发短期中间令牌，提交验证码后再签发正式令牌
+	if user.TOTPEnabled {
+		mfa, err := auth.Tokens.IssueMFAToken(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "生成令牌失败",
+			})
+			return
+		}
+		recordLoginAttempt(c, user, identifier, db.LoginMFARequired)
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "请输入两步验证码",
+			"data": gin.H{
+				"mfa_required": true,
+				"mfa_token":    mfa.Token,
+				"expire":       mfa.ExpiresAt.Unix(),
+			},
+		})
+		return
+	}
+
+	// 登录成功，清除账号的失败计数并递减IP的失败计数
+	if err := auth.Guard.Succeed(accountKey, ip); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "服务器内部错误",
+		})
+		return
+	}
+
+	// 签发访问令牌和刷新令牌
+	data, err := issueTokens(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -85,19 +155,262 @@ func Login(c *gin.Context) {
 		})
 		return
 	}
+	recordLoginAttempt(c, user, identifier, db.LoginSuccess)
 
 	// 返回令牌和用户信息
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "登录成功",
+		"data":    data,
+	})
+}
+
+// findLoginUser 按邮箱或用户名查找用户，包含 @ 时优先按邮箱查找，找不到时返回 nil
+func findLoginUser(identifier string) *db.User {
+	if strings.Contains(identifier, "@") {
+		if user, err := db.GetUserByEmail(identifier); err == nil {
+			return user
+		}
+	}
+	user, err := db.GetUserByUsername(identifier)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// accountBlocked 检查账号是否被封禁或需要重置密码，返回审计原因和提示，可以登录时返回空字符串
+func accountBlocked(user *db.User) (string, string) {
+	if user.IsBanned(auth.Tokens.Now()) {
+		return db.LoginBanned, user.BanMessage()
+	}
+	if user.MustResetPassword {
+		return db.LoginResetRequired, "管理员要求重置密码，请通过找回密码设置新密码"
+	}
+	return "", ""
+}
+
+// loginFailed 记录一次登录失败，因此触发锁定或出错时直接返回响应并返回 false
+func loginFailed(c *gin.Context, accountKey, ip string) bool {
+	remaining, err := auth.Guard.Fail(accountKey, ip)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "服务器内部错误",
+		})
+		return false
+	}
+	if remaining > 0 {
+		respondLoginLocked(c, remaining)
+		return false
+	}
+	return true
+}
+
+// respondLoginLocked 返回账号或IP被锁定的响应
+func respondLoginLocked(c *gin.Context, remaining time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"code":    429,
+		"message": fmt.Sprintf("登录失败次数过多，请 %d 分钟后再试", int(math.Ceil(remaining.Minutes()))),
+	})
+}
+
+// recordLoginAttempt 写入登录审计记录，写入失败只记录日志，不影响登录
+func recordLoginAttempt(c *gin.Context, user *db.User, account, reason string) {
+	attempt := &db.LoginAttempt{
+		Account:   truncateRunes(account, 100),
+		IP:        c.ClientIP(),
+		UserAgent: truncateRunes(c.Request.UserAgent(), 255),
+		Success:   reason == db.LoginSuccess,
+		Reason:    reason,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	if err := db.RecordLoginAttempt(attempt); err != nil {
+		log.Printf("记录登录审计失败: %v", err)
+	}
+}
+
+// truncateRunes 按字符截断字符串，避免超出数据库字段长度
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// issueTokens 签发访问令牌，并开启新的刷新令牌家族
+func issueTokens(user *db.User) (*LoginResponse, error) {
+	access, err := auth.Tokens.IssueAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := auth.Tokens.IssueRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	familyID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.CreateRefreshToken(user.ID, familyID, refresh.Hash, refresh.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token:         access.Token,
+		RefreshToken:  refresh.Token,
+		User:          *user,
+		Expire:        access.ExpiresAt.Unix(),
+		RefreshExpire: refresh.ExpiresAt.Unix(),
+	}, nil
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌（刷新令牌每次使用后轮换）
+func Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求格式不正确",
+		})
+		return
+	}
+
+	// 生成新的刷新令牌
+	refresh, err := auth.Tokens.IssueRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "生成令牌失败",
+		})
+		return
+	}
+
+	// 轮换刷新令牌
+	userID, err := db.RotateRefreshToken(auth.HashToken(req.RefreshToken), refresh.Hash, auth.Tokens.Now(), refresh.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenInvalid) || errors.Is(err, db.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "刷新令牌失败",
+		})
+		return
+	}
+
+	// 使用最新的用户信息签发访问令牌
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "用户不存在或已被删除",
+		})
+		return
+	}
+	if _, message := accountBlocked(user); message != "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": message,
+		})
+		return
+	}
+	access, err := auth.Tokens.IssueAccessToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "生成令牌失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "刷新令牌成功",
 		"data": LoginResponse{
-			Token:  tokenString,
-			User:   *user,
-			Expire: expireTime.Unix(),
+			Token:         access.Token,
+			RefreshToken:  refresh.Token,
+			User:          *user,
+			Expire:        access.ExpiresAt.Unix(),
+			RefreshExpire: refresh.ExpiresAt.Unix(),
 		},
 	})
 }
 
+// GetJWKS 发布用于验证访问令牌的公钥（JWKS），供其他服务验证令牌
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.Keys.JWKS())
+}
+
+// LogoutRequest 退出登录请求结构，提供刷新令牌时一并吊销其登录会话
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout 退出当前登录：吊销当前访问令牌及对应的刷新令牌
+func Logout(c *gin.Context) {
+	var req LogoutRequest
+	// 请求体可选
+	_ = c.ShouldBindJSON(&req)
+
+	// 吊销当前访问令牌，直到其原本的过期时间
+	if err := auth.Revocations.Revoke(c.GetString("token_id"), c.GetTime("token_expires_at")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "退出登录失败",
+		})
+		return
+	}
+
+	// 吊销刷新令牌所在的登录会话
+	if req.RefreshToken != "" {
+		if err := db.RevokeRefreshTokenFamily(auth.HashToken(req.RefreshToken)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "退出登录失败",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已退出登录",
+	})
+}
+
+// LogoutAll 退出所有设备：此前签发的访问令牌全部失效，并吊销全部刷新令牌
+func LogoutAll(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if err := db.InvalidateUserTokens(userID, auth.Tokens.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "退出登录失败",
+		})
+		return
+	}
+	if err := db.RevokeUserRefreshTokens(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "退出登录失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已退出所有设备",
+	})
+}
+
 // Register 处理用户注册请求
 func Register(c *gin.Context) {
 	var registerReq struct {
@@ -114,6 +427,23 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// 用户名可用于登录，必须唯一
+	taken, err := db.UsernameExists(registerReq.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "服务器内部错误",
+		})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": db.ErrUsernameTaken.Error(),
+		})
+		return
+	}
+
 	// 创建新用户
 	user := &db.User{
 		Username: registerReq.Username,
@@ -124,6 +454,13 @@ func Register(c *gin.Context) {
 
 	// 保存到数据库
 	if err := db.CreateUser(user); err != nil {
+		if errors.Is(err, db.ErrUsernameTaken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "注册失败: " + err.Error(),
@@ -131,13 +468,19 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// 发送邮箱验证邮件，验证前账号只读；发送失败时可登录后重新发送
+	if err := sendEmailToken(user, auth.PurposeVerifyEmail); err != nil {
+		log.Printf("发送验证邮件失败: %v", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "注册成功",
+		"message": "注册成功，请查收验证邮件",
 		"data": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
+			"id":             user.ID,
+			"username":       user.Username,
+			"email":          user.Email,
+			"email_verified": user.EmailVerified,
 		},
 	})
 }
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// totpIssuer 身份验证器应用中显示的服务名称
+const totpIssuer = "LawConnect"
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// MFACodeRequest 两步验证码请求结构，验证码和恢复码二选一
+type MFACodeRequest struct {
+	Code         string `json:"code"`          // 身份验证器应用中的 6 位验证码
+	RecoveryCode string `json:"recovery_code"` // 一次性恢复码
+}
+
+// MFALoginRequest 两步登录第二步的请求结构
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	MFACodeRequest
+}
+
+// DisableMFARequest 关闭两步验证的请求结构，需要同时提供密码和验证码
+type DisableMFARequest struct {
+	Password string `json:"password" binding:"required"`
+	MFACodeRequest
+}
+
+// MFAPolicy 两步验证策略
+type MFAPolicy struct {
+	RequireAdminMFA bool `json:"require_admin_mfa"` // 管理员必须启用两步验证
+}
+
+// verifySecondFactor 校验用户的 TOTP 验证码或恢复码，验证码通过后记录时间步防止重放
+func verifySecondFactor(userID int, req MFACodeRequest) (bool, error) {
+	state, err := db.GetTOTPState(userID)
+	if err != nil {
+		return false, err
+	}
+	if !state.Enabled {
+		return false, nil
+	}
+
+	if req.Code != "" {
+		step, ok := auth.ValidateTOTP(state.Secret, req.Code, auth.Tokens.Now(), state.LastStep)
+		if !ok {
+			return false, nil
+		}
+		return db.RecordTOTPStep(userID, step)
+	}
+	if req.RecoveryCode != "" {
+		return db.UseRecoveryCode(userID, auth.HashToken(auth.NormalizeRecoveryCode(req.RecoveryCode)))
+	}
+	return false, nil
+}
+
+// newRecoveryCodes 生成恢复码，返回明文（只展示一次）和用于保存的哈希
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(auth.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// GetMFAStatus 获取当前用户的两步验证状态
+func GetMFAStatus(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	state, err := db.GetTOTPState(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询两步验证状态失败"})
+		return
+	}
+	remaining, err := db.CountRecoveryCodes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询两步验证状态失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"enabled":                  state.Enabled,
+			"recovery_codes_remaining": remaining,
+		},
+	})
+}
+
+// SetupMFA 生成新的 TOTP 密钥和扫码用的 otpauth URI，验证码确认后才会启用
+func SetupMFA(c *gin.Context) {
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已启用两步验证"})
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成密钥失败"})
+		return
+	}
+	if err := db.SetTOTPSecret(user.ID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存密钥失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "请使用身份验证器应用扫描二维码，并提交验证码完成绑定",
+		"data": gin.H{
+			"secret": secret,
+			"uri":    auth.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+		},
+	})
+}
+
+// EnableMFA 提交验证码确认绑定，启用两步验证并返回恢复码
+func EnableMFA(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	state, err := db.GetTOTPState(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询两步验证状态失败"})
+		return
+	}
+	if state.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "已启用两步验证"})
+		return
+	}
+	if state.Secret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请先生成两步验证密钥"})
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(state.Secret, req.Code, auth.Tokens.Now(), state.LastStep)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证码不正确"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成恢复码失败"})
+		return
+	}
+	if err := db.EnableTOTP(userID, step, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "启用两步验证失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已启用两步验证，请妥善保存恢复码，每个恢复码只能使用一次",
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// DisableMFA 关闭两步验证，需要当前密码和验证码（或恢复码）
+func DisableMFA(c *gin.Context) {
+	var req DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "未启用两步验证"})
+		return
+	}
+
+	// 策略要求管理员启用两步验证时不允许关闭
+	if user.IsAdmin() {
+		required, err := db.GetBoolSetting(db.SettingRequireAdminMFA, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+			return
+		}
+		if required {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "管理员必须启用两步验证"})
+			return
+		}
+	}
+
+	if !user.CheckPassword(req.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "密码不正确"})
+		return
+	}
+	verified, err := verifySecondFactor(user.ID, req.MFACodeRequest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+	if !verified {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证码不正确"})
+		return
+	}
+
+	if err := db.DisableTOTP(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "关闭两步验证失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已关闭两步验证"})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，原有恢复码全部作废
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	// 只接受验证码，避免用恢复码无限续期
+	verified, err := verifySecondFactor(userID, MFACodeRequest{Code: req.Code})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+	if !verified {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证码不正确"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成恢复码失败"})
+		return
+	}
+	if err := db.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成恢复码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已重新生成恢复码，原有恢复码已失效",
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// LoginMFA 两步登录的第二步：使用密码验证后得到的中间令牌和验证码换取正式令牌
+func LoginMFA(c *gin.Context) {
+	var req MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	claims, err := auth.Tokens.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "两步验证已过期，请重新登录"})
+		return
+	}
+	// 中间令牌只能使用一次
+	revoked, err := auth.Revocations.IsRevoked(claims.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+	if revoked {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "两步验证已过期，请重新登录"})
+		return
+	}
+
+	user, err := db.GetUserByID(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "用户不存在或已被删除"})
+		return
+	}
+	if reason, message := accountBlocked(user); reason != "" {
+		recordLoginAttempt(c, user, user.Username, reason)
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": message})
+		return
+	}
+
+	// 验证码错误同样计入账号和IP的失败次数，防止穷举验证码
+	accountKey := auth.LoginAccountKey(user, "")
+	ip := c.ClientIP()
+	remaining, err := auth.Guard.Locked(accountKey, ip)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+	if remaining > 0 {
+		recordLoginAttempt(c, user, user.Username, db.LoginLocked)
+		respondLoginLocked(c, remaining)
+		return
+	}
+
+	verified, err := verifySecondFactor(user.ID, req.MFACodeRequest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+	if !verified {
+		recordLoginAttempt(c, user, user.Username, db.LoginBadMFACode)
+		if !loginFailed(c, accountKey, ip) {
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "验证码不正确"})
+		return
+	}
+	if err := auth.Guard.Succeed(accountKey, ip); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+
+	if err := auth.Revocations.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "服务器内部错误"})
+		return
+	}
+	data, err := issueTokens(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成令牌失败"})
+		return
+	}
+	recordLoginAttempt(c, user, user.Username, db.LoginSuccess)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "登录成功",
+		"data":    data,
+	})
+}
+
+// GetMFAPolicy 获取两步验证策略
+func GetMFAPolicy(c *gin.Context) {
+	required, err := db.GetBoolSetting(db.SettingRequireAdminMFA, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询策略失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data":    MFAPolicy{RequireAdminMFA: required},
+	})
+}
+
+// UpdateMFAPolicy 修改两步验证策略
+func UpdateMFAPolicy(c *gin.Context) {
+	var req MFAPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	// 开启策略前当前管理员必须已启用两步验证，避免把自己锁在管理后台之外
+	value, _ := c.Get("user")
+	if user, ok := value.(*db.User); ok && req.RequireAdminMFA && !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请先为当前账号启用两步验证"})
+		return
+	}
+
+	if err := db.SetSetting(db.SettingRequireAdminMFA, strconv.FormatBool(req.RequireAdminMFA)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存策略失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data":    req,
+	})
+}
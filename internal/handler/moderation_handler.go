@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/config"
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewCommentsRequest 批量审核评论的请求结构，单次最多审核 100 条
+type ReviewCommentsRequest struct {
+	IDs    []int  `json:"ids" binding:"required,min=1,max=100"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// commentStatus 根据审核模式决定新评论的初始状态
+func commentStatus(c *gin.Context) (int, error) {
+	// 拥有评论管理权限的用户（管理员、审核员）的评论无需审核
+	moderator, err := userHasPermission(c, db.PermCommentModerate)
+	if err != nil {
+		return 0, err
+	}
+	if moderator {
+		return db.CommentVisible, nil
+	}
+
+	switch config.GlobalConfig.Moderation.Mode {
+	case config.ModerationReview:
+		return db.CommentPending, nil
+	case config.ModerationTrusted:
+		approved, err := db.CountApprovedComments(c.GetInt("user_id"))
+		if err != nil {
+			return 0, err
+		}
+		if approved >= config.GlobalConfig.Moderation.TrustedApproved {
+			return db.CommentVisible, nil
+		}
+		return db.CommentPending, nil
+	default:
+		return db.CommentVisible, nil
+	}
+}
+
+// commentSubmittedMessage 根据评论状态返回提示信息
+func commentSubmittedMessage(status int, visible string) string {
+	if status == db.CommentPending {
+		return "已提交，等待审核"
+	}
+	return visible
+}
+
+// GetPendingComments 获取待审核评论列表（管理员）
+func GetPendingComments(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	comments, total, err := db.ListPendingComments(page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询待审核评论失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":      comments,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// ApproveComments 批量通过评论（管理员）
+func ApproveComments(c *gin.Context) {
+	reviewComments(c, true)
+}
+
+// RejectComments 批量驳回评论（管理员）
+func RejectComments(c *gin.Context) {
+	reviewComments(c, false)
+}
+
+// reviewComments 批量审核评论并通知作者
+func reviewComments(c *gin.Context, approve bool) {
+	var req ReviewCommentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误"})
+		return
+	}
+
+	reviewed, err := db.ReviewComments(req.IDs, approve, req.Reason, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "审核失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "审核成功",
+		"data": gin.H{
+			"reviewed": reviewed,
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications 获取当前用户的通知
+func GetNotifications(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	notifications, unread, err := db.GetNotifications(c.GetInt("user_id"), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询通知失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":   notifications,
+			"unread": unread,
+		},
+	})
+}
+
+// MarkNotificationsRead 将当前用户的通知全部标记为已读
+func MarkNotificationsRead(c *gin.Context) {
+	if err := db.MarkNotificationsRead(c.GetInt("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "操作失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功"})
+}
@@ -0,0 +1,354 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/region"
+	"github.com/gin-gonic/gin"
+)
+
+// dateLayout 政策日期格式
+const dateLayout = "2006-01-02"
+
+// PolicyMetaRequest 政策结构化信息的请求结构
+type PolicyMetaRequest struct {
+	DocNumber     string `json:"doc_number" binding:"max=100"`
+	Authority     string `json:"authority" binding:"required,max=100"`
+	PublishDate   string `json:"publish_date" binding:"required,datetime=2006-01-02"`
+	EffectiveDate string `json:"effective_date" binding:"omitempty,datetime=2006-01-02"`
+	Status        string `json:"status" binding:"required,oneof=effective amended repealed"`
+}
+
+// PolicyRequest 发布政策的请求结构：文章内容（含适用地区）和政策信息
+type PolicyRequest struct {
+	ArticleRequest
+	PolicyMetaRequest
+}
+
+// PolicyInterpretationRequest 关联解读文章的请求结构
+type PolicyInterpretationRequest struct {
+	ArticleID int `json:"article_id" binding:"required"`
+}
+
+// meta 转换为数据库模型
+func (r *PolicyMetaRequest) meta() *db.PolicyMeta {
+	meta := &db.PolicyMeta{
+		DocNumber:   r.DocNumber,
+		Authority:   r.Authority,
+		PublishDate: r.PublishDate,
+		Status:      r.Status,
+	}
+	if r.EffectiveDate != "" {
+		meta.EffectiveDate = &r.EffectiveDate
+	}
+	return meta
+}
+
+// ListPolicies 按发文字号、发布机关、地区、效力状态和日期筛选政策
+// 查询参数：doc_number、authority（模糊匹配）、region（含下级地区）、status、published_from、published_to、
+// effective_from、effective_to（日期格式 2006-01-02）、category_id、sort（publish_date、effective_date、latest）、page、page_size
+func ListPolicies(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filter := db.PolicyFilter{
+		DocNumber:     c.Query("doc_number"),
+		Authority:     c.Query("authority"),
+		RegionCode:    c.Query("region"),
+		Status:        c.Query("status"),
+		PublishedFrom: c.Query("published_from"),
+		PublishedTo:   c.Query("published_to"),
+		EffectiveFrom: c.Query("effective_from"),
+		EffectiveTo:   c.Query("effective_to"),
+		Sort:          db.PolicySort(c.DefaultQuery("sort", string(db.PolicySortPublished))),
+	}
+	if filter.Status != "" && !db.ValidPolicyStatus(filter.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "status 应为 effective、amended 或 repealed"})
+		return
+	}
+	if !filter.Sort.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "sort 应为 publish_date、effective_date 或 latest"})
+		return
+	}
+	if filter.RegionCode != "" && !region.ValidCode(filter.RegionCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的地区代码"})
+		return
+	}
+	for _, date := range []string{filter.PublishedFrom, filter.PublishedTo, filter.EffectiveFrom, filter.EffectiveTo} {
+		if _, err := time.Parse(dateLayout, date); date != "" && err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "日期格式应为 2006-01-02"})
+			return
+		}
+	}
+	if v := c.Query("category_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的分类ID"})
+			return
+		}
+		filter.CategoryID = id
+	}
+
+	respondPolicies(c, filter, page)
+}
+
+// GetArticlePolicies 获取文章解读的政策
+func GetArticlePolicies(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的文章ID"})
+		return
+	}
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	respondPolicies(c, db.PolicyFilter{InterpretedBy: articleID}, page)
+}
+
+// GetNearbyPolicies 身边的政策：按当前用户资料中的地区，推荐该地区及其上级、下级地区的政策
+// 查询参数：status、sort（publish_date、effective_date、latest）、page、page_size
+func GetNearbyPolicies(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	profile, err := db.GetProfile(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询资料失败"})
+		return
+	}
+	if profile.RegionCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请先在个人资料中设置所在地区"})
+		return
+	}
+
+	filter := db.PolicyFilter{
+		NearRegion: profile.RegionCode,
+		Status:     c.Query("status"),
+		Sort:       db.PolicySort(c.DefaultQuery("sort", string(db.PolicySortPublished))),
+	}
+	if filter.Status != "" && !db.ValidPolicyStatus(filter.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "status 应为 effective、amended 或 repealed"})
+		return
+	}
+	if !filter.Sort.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "sort 应为 publish_date、effective_date 或 latest"})
+		return
+	}
+
+	respondPolicies(c, filter, page)
+}
+
+// respondPolicies 查询政策列表并写入响应
+func respondPolicies(c *gin.Context, filter db.PolicyFilter, page db.Pagination) {
+	list, total, err := db.ListPolicies(filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询政策失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":      list,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// GetPolicyDetail 获取政策详情和解读文章
+// 查询参数：page、page_size、cursor 为解读文章的分页参数
+func GetPolicyDetail(c *gin.Context) {
+	policyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的政策ID"})
+		return
+	}
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	policy, err := db.GetPolicy(policyID)
+	if err != nil {
+		respondPolicyError(c, "查询政策失败", err)
+		return
+	}
+	interpretations, err := db.ListPolicyInterpretations(policyID, db.SortByLatest, page)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询解读文章失败"})
+		return
+	}
+	if !linkCitations(c, &policy.Article, nil) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"policy":          policy,
+			"interpretations": interpretations,
+		},
+	})
+}
+
+// CreatePolicy 发布政策
+func CreatePolicy(c *gin.Context) {
+	var req PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	// 验证分类是否存在以及发布权限，验证地区是否存在
+	if !checkCategory(c, req.CategoryID) || !checkRegion(c, req.RegionCode) {
+		return
+	}
+
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Title, &req.Content)
+	if !ok {
+		return
+	}
+
+	article := &db.Article{
+		Title:      req.Title,
+		Content:    req.Content,
+		CoverURL:   req.CoverURL,
+		CategoryID: req.CategoryID,
+		RegionCode: req.RegionCode,
+		UserID:     c.GetInt("user_id"),
+		IsVisible:  articleStatus(checked),
+	}
+	if err := db.CreatePolicy(article, req.meta()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "发布政策失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": articleSubmittedMessage(article.IsVisible, "发布成功"),
+		"data": gin.H{
+			"policy_id":  article.ID,
+			"is_visible": article.IsVisible,
+		},
+	})
+}
+
+// UpdatePolicyMeta 修改政策的发文字号、发布机关、日期和效力状态，正文和适用地区通过编辑文章修改
+func UpdatePolicyMeta(c *gin.Context) {
+	policyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的政策ID"})
+		return
+	}
+	var req PolicyMetaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := db.UpdatePolicyMeta(policyID, req.meta()); err != nil {
+		respondPolicyError(c, "修改政策失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "修改成功", "data": gin.H{"policy_id": policyID}})
+}
+
+// AddPolicyInterpretation 将文章关联为政策的解读（解读文章的作者或政策编辑）
+func AddPolicyInterpretation(c *gin.Context) {
+	policyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的政策ID"})
+		return
+	}
+	var req PolicyInterpretationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+	if !canLinkInterpretation(c, req.ArticleID) {
+		return
+	}
+
+	if err := db.AddPolicyInterpretation(policyID, req.ArticleID); err != nil {
+		respondPolicyError(c, "关联解读失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "关联成功", "data": gin.H{"policy_id": policyID, "article_id": req.ArticleID}})
+}
+
+// RemovePolicyInterpretation 取消文章与政策的解读关联（解读文章的作者或政策编辑）
+func RemovePolicyInterpretation(c *gin.Context) {
+	policyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的政策ID"})
+		return
+	}
+	articleID, err := strconv.Atoi(c.Param("article_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的文章ID"})
+		return
+	}
+	if !canLinkInterpretation(c, articleID) {
+		return
+	}
+
+	if err := db.RemovePolicyInterpretation(policyID, articleID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "取消关联失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已取消关联", "data": gin.H{"policy_id": policyID, "article_id": articleID}})
+}
+
+// canLinkInterpretation 检查当前用户能否修改文章的解读关联：文章作者或拥有政策编辑权限，不满足时写入错误响应
+func canLinkInterpretation(c *gin.Context, articleID int) bool {
+	article, err := db.GetArticleByID(articleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "文章不存在或已被删除"})
+		return false
+	}
+	if article.UserID == c.GetInt("user_id") {
+		return true
+	}
+
+	has, err := userHasPermission(c, db.PermPolicyEdit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询权限失败"})
+		return false
+	}
+	if !has {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "只有文章作者或政策编辑可以修改解读关联"})
+		return false
+	}
+	return true
+}
+
+// respondPolicyError 将政策操作错误转换为响应
+func respondPolicyError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrPolicyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case errors.Is(err, db.ErrInterpretationSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message})
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/config"
+	"github.com/gin-gonic/gin"
+)
+
+// QuestionRequest 提问的请求结构，category_id 为空时使用配置的问答分类
+type QuestionRequest struct {
+	Title      string `json:"title" binding:"required,max=255"`
+	Content    string `json:"content" binding:"required"`
+	CategoryID int    `json:"category_id"`
+	Bounty     int    `json:"bounty" binding:"min=0"`
+}
+
+// AcceptAnswerRequest 采纳回答的请求结构
+type AcceptAnswerRequest struct {
+	CommentID int `json:"comment_id" binding:"required"`
+}
+
+// ListQuestions 生成问题列表处理程序，sort 为默认排序方式，categoryID 为默认分类（0 表示不限）
+// 查询参数：status（unanswered、unsolved、solved）、lawyer（true 时只看有认证律师回答的问题）、
+// sort（latest、bounty、answers）、category_id、page、page_size
+func ListQuestions(sort db.QuestionSort, categoryID int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := bindPagination(c)
+		if !ok {
+			return
+		}
+
+		filter := db.QuestionFilter{
+			Status:         c.Query("status"),
+			LawyerAnswered: c.Query("lawyer") == "true",
+			Sort:           db.QuestionSort(c.DefaultQuery("sort", string(sort))),
+			CategoryID:     categoryID,
+		}
+		switch filter.Status {
+		case "", db.QuestionUnanswered, db.QuestionUnsolved, db.QuestionSolved:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "status 应为 unanswered、unsolved 或 solved"})
+			return
+		}
+		if !filter.Sort.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "sort 应为 latest、bounty 或 answers"})
+			return
+		}
+		if v := c.Query("category_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的分类ID"})
+				return
+			}
+			filter.CategoryID = id
+		}
+
+		list, total, err := db.ListQuestions(filter, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询问题失败"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "成功",
+			"data": gin.H{
+				"list":      list,
+				"total":     total,
+				"page":      page.Page,
+				"page_size": page.PageSize,
+			},
+		})
+	}
+}
+
+// GetQuestionDetail 获取问题详情和回答，被采纳的回答排在最前，其余按得票倒序
+// 查询参数：depth 为返回的讨论层数（回答为第 1 层）
+func GetQuestionDetail(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的问题ID"})
+		return
+	}
+
+	question, err := db.GetQuestion(questionID)
+	if err != nil {
+		respondQuestionError(c, "查询问题失败", err)
+		return
+	}
+	answers, err := db.GetQuestionAnswers(question, commentDepth(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询回答失败"})
+		return
+	}
+	if !linkCitations(c, &question.Article, answers) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"question": question,
+			"answers":  answers,
+		},
+	})
+}
+
+// CreateQuestion 提问，悬赏积分从提问者积分中扣除
+func CreateQuestion(c *gin.Context) {
+	var req QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+	if req.Bounty > config.GlobalConfig.QA.MaxBounty {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "悬赏积分不能超过 " + strconv.Itoa(config.GlobalConfig.QA.MaxBounty)})
+		return
+	}
+	if req.CategoryID == 0 {
+		req.CategoryID = config.GlobalConfig.QA.CategoryID
+	}
+
+	// 验证分类是否存在以及发布权限
+	if !checkCategory(c, req.CategoryID) {
+		return
+	}
+
+	// 敏感词过滤
+	checked, ok := checkContent(c, &req.Title, &req.Content)
+	if !ok {
+		return
+	}
+
+	article := &db.Article{
+		Title:      req.Title,
+		Content:    req.Content,
+		CategoryID: req.CategoryID,
+		UserID:     c.GetInt("user_id"),
+		IsVisible:  articleStatus(checked),
+	}
+	if err := db.CreateQuestion(article, req.Bounty); err != nil {
+		respondQuestionError(c, "提问失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": articleSubmittedMessage(article.IsVisible, "提问成功"),
+		"data": gin.H{
+			"question_id": article.ID,
+			"is_visible":  article.IsVisible,
+		},
+	})
+}
+
+// AcceptAnswer 采纳回答（仅提问者），每个问题只能采纳一个回答
+func AcceptAnswer(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的问题ID"})
+		return
+	}
+	var req AcceptAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := db.AcceptAnswer(questionID, req.CommentID, c.GetInt("user_id"), config.GlobalConfig.QA.AcceptReward); err != nil {
+		respondQuestionError(c, "采纳回答失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "采纳成功",
+		"data": gin.H{
+			"question_id": questionID,
+			"comment_id":  req.CommentID,
+		},
+	})
+}
+
+// GetUserPoints 获取当前用户的积分余额和积分变动记录
+func GetUserPoints(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return
+	}
+
+	logs, total, err := db.ListPointLogs(user.ID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询积分记录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"points":    user.Points,
+			"list":      logs,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// respondQuestionError 将问答操作错误转换为响应
+func respondQuestionError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrQuestionNotFound), errors.Is(err, db.ErrAnswerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case errors.Is(err, db.ErrQuestionForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": err.Error()})
+	case errors.Is(err, db.ErrAnswerAccepted), errors.Is(err, db.ErrAcceptOwnAnswer), errors.Is(err, db.ErrInsufficientPoints):
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message})
+	}
+}
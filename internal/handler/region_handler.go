@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/region"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRegions 获取下级行政区划
+// 查询参数：parent 为上级区划代码，为空时返回全部省级区划
+func GetRegions(c *gin.Context) {
+	parent := c.Query("parent")
+	if parent != "" && !region.ValidCode(parent) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的地区代码"})
+		return
+	}
+
+	regions, err := db.ListRegions(parent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询地区失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": regions})
+}
+
+// GetRegionDetail 获取行政区划及其上级区划路径（省、市、县）
+func GetRegionDetail(c *gin.Context) {
+	code := c.Param("code")
+	if !region.ValidCode(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的地区代码"})
+		return
+	}
+
+	path, err := db.GetRegionPath(code)
+	if err != nil {
+		if errors.Is(err, db.ErrRegionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询地区失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"region": path[len(path)-1],
+			"path":   path,
+		},
+	})
+}
+
+// checkRegion 验证行政区划代码格式正确且存在，空代码表示不限地区，不满足时直接写入错误响应
+func checkRegion(c *gin.Context, code string) bool {
+	if code == "" {
+		return true
+	}
+	if !region.ValidCode(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的地区代码"})
+		return false
+	}
+
+	exists, err := db.RegionExists(code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询地区失败"})
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "地区不存在"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// RoleRequest 创建角色的请求结构
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest 修改角色的请求结构，permissions 会整体替换角色原有的权限
+type UpdateRoleRequest struct {
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRolesRequest 设置用户额外角色的请求结构
+type UserRolesRequest struct {
+	RoleIDs []int `json:"role_ids"`
+}
+
+// GetPermissions 获取所有权限（管理员）
+func GetPermissions(c *gin.Context) {
+	permissions, err := db.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询权限失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": permissions})
+}
+
+// GetRoles 获取所有角色及其权限（管理员）
+func GetRoles(c *gin.Context) {
+	roles, err := db.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询角色失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": roles})
+}
+
+// CreateRole 创建角色（管理员）
+func CreateRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	role := &db.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions}
+	if err := db.CreateRole(role); err != nil {
+		respondRoleError(c, "创建角色失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "创建成功", "data": gin.H{"role_id": role.ID}})
+}
+
+// UpdateRole 修改角色说明和权限（管理员）
+func UpdateRole(c *gin.Context) {
+	id, ok := roleIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	role := &db.Role{ID: id, Description: req.Description, Permissions: req.Permissions}
+	if err := db.UpdateRole(role); err != nil {
+		respondRoleError(c, "修改角色失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "修改成功", "data": gin.H{"role_id": id}})
+}
+
+// DeleteRole 删除角色（管理员）
+func DeleteRole(c *gin.Context) {
+	id, ok := roleIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteRole(id); err != nil {
+		respondRoleError(c, "删除角色失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功", "data": gin.H{"role_id": id}})
+}
+
+// GetUserRoles 获取用户额外拥有的角色和最终的权限（管理员）
+func GetUserRoles(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+	roles, err := db.GetUserRoles(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询角色失败"})
+		return
+	}
+	permissions, err := db.GetUserPermissions(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询权限失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"role":        user.Role,
+			"roles":       roles,
+			"permissions": permissions,
+		},
+	})
+}
+
+// SetUserRoles 设置用户在基础角色之外额外拥有的角色（管理员）
+func SetUserRoles(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var req UserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	if err := db.SetUserRoles(userID, req.RoleIDs); err != nil {
+		respondRoleError(c, "设置角色失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "设置成功"})
+}
+
+// roleIDParam 解析路径中的角色ID
+func roleIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的角色ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondRoleError 将角色操作错误转换为响应
+func respondRoleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrRoleNotFound), errors.Is(err, db.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	case errors.Is(err, db.ErrRoleNameTaken), errors.Is(err, db.ErrSystemRole),
+		errors.Is(err, db.ErrAdminRole), errors.Is(err, db.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message})
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// 搜索关键词长度限制（ngram 分词默认按 2 个字符切分）
+const (
+	minKeywordLength = 2
+	maxKeywordLength = 100
+)
+
+// Search 全文搜索文章和评论
+// 查询参数：q 关键词，type 为 article/comment/all（默认 all），category_id 限定分类子树，page、page_size 分页
+func Search(c *gin.Context) {
+	keyword := strings.TrimSpace(c.Query("q"))
+	if n := utf8.RuneCountInString(keyword); n < minKeywordLength || n > maxKeywordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "搜索关键词长度应为2到100个字符"})
+		return
+	}
+
+	categoryID := 0
+	if v := c.Query("category_id"); v != "" {
+		var err error
+		if categoryID, err = strconv.Atoi(v); err != nil || categoryID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的分类ID"})
+			return
+		}
+	}
+
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	searchType := c.DefaultQuery("type", "all")
+	data := gin.H{}
+	if searchType == "all" || searchType == db.SearchTypeArticle {
+		articles, err := db.SearchArticles(keyword, categoryID, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "搜索失败"})
+			return
+		}
+		data["articles"] = articles
+	}
+	if searchType == "all" || searchType == db.SearchTypeComment {
+		comments, err := db.SearchComments(keyword, categoryID, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "搜索失败"})
+			return
+		}
+		data["comments"] = comments
+	}
+	if len(data) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的搜索类型"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": data})
+}
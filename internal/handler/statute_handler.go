@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/VanVodkaer/LawConnect-API/utils/config"
+	"github.com/VanVodkaer/LawConnect-API/utils/statute"
+	"github.com/gin-gonic/gin"
+)
+
+// 导入文件的最大长度（字节）
+const maxStatuteImportSize = 20 << 20
+
+// 引用来源
+const (
+	citingArticles = "article" // 正文引用了条文的文章
+	citingComments = "comment" // 引用了条文的评论
+)
+
+// ListStatutes 按名称查询法规
+// 查询参数：q 为全称或简称的关键词，page、page_size
+func ListStatutes(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	list, total, err := db.ListStatutes(c.Query("q"), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询法规失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"list":      list,
+			"total":     total,
+			"page":      page.Page,
+			"page_size": page.PageSize,
+		},
+	})
+}
+
+// GetStatuteDetail 获取法规在指定日期施行的版本的章节和条文，以及全部版本
+// 查询参数：date 为日期（2006-01-02），默认今天
+func GetStatuteDetail(c *gin.Context) {
+	id, ok := statuteIDParam(c)
+	if !ok {
+		return
+	}
+	date, ok := statuteDate(c)
+	if !ok {
+		return
+	}
+
+	s, err := db.GetStatute(id)
+	if err != nil {
+		respondStatuteError(c, "查询法规失败", err)
+		return
+	}
+	versions, err := db.ListStatuteVersions(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询法规版本失败"})
+		return
+	}
+	version, err := db.GetStatuteVersionAt(id, date)
+	if err != nil {
+		respondStatuteError(c, "查询法规版本失败", err)
+		return
+	}
+	nodes, articles, err := db.GetStatuteContents(version.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询条文失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"statute":  s,
+			"version":  version,
+			"versions": versions,
+			"nodes":    nodes,
+			"articles": articles,
+		},
+	})
+}
+
+// GetStatuteArticle 获取条文在指定日期施行的版本中的内容，以及该条在各版本中的内容
+// 路径参数 number 为条号，如 1165，“第一百三十三条之一”为 133-1；查询参数：date 为日期（2006-01-02），默认今天
+func GetStatuteArticle(c *gin.Context) {
+	id, ok := statuteIDParam(c)
+	if !ok {
+		return
+	}
+	number, subNumber, ok := statuteArticleParam(c)
+	if !ok {
+		return
+	}
+	date, ok := statuteDate(c)
+	if !ok {
+		return
+	}
+
+	s, err := db.GetStatute(id)
+	if err != nil {
+		respondStatuteError(c, "查询法规失败", err)
+		return
+	}
+	version, err := db.GetStatuteVersionAt(id, date)
+	if err != nil {
+		respondStatuteError(c, "查询法规版本失败", err)
+		return
+	}
+	history, err := db.GetStatuteArticleHistory(id, number, subNumber)
+	if err != nil {
+		respondStatuteError(c, "查询条文失败", err)
+		return
+	}
+
+	// 条文可能在该版本中被删除
+	var current *db.StatuteArticle
+	for _, v := range history {
+		if v.VersionID == version.ID {
+			current = &db.StatuteArticle{
+				Number:    number,
+				SubNumber: subNumber,
+				Label:     statute.ArticleLabel(number, subNumber),
+				Ref:       statute.ArticleRef(number, subNumber),
+				Content:   v.Content,
+			}
+			break
+		}
+	}
+	if current == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "该版本中没有此条"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"statute": s,
+			"version": version,
+			"article": current,
+			"history": history,
+		},
+	})
+}
+
+// GetStatuteArticleCitations 反向查询引用了条文的文章或评论
+// 查询参数：source 为 article（正文引用了该条的文章，默认）或 comment（引用了该条的评论），
+// sort（article 时可选 latest、likes、comments）、page、page_size、cursor（article 时可用）
+func GetStatuteArticleCitations(c *gin.Context) {
+	id, ok := statuteIDParam(c)
+	if !ok {
+		return
+	}
+	number, subNumber, ok := statuteArticleParam(c)
+	if !ok {
+		return
+	}
+	page, ok := bindCursorPagination(c)
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("source", citingArticles) {
+	case citingArticles:
+		sort := db.ArticleSort(c.DefaultQuery("sort", string(db.SortByLatest)))
+		if !sort.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "sort 应为 latest、likes 或 comments"})
+			return
+		}
+		result, err := db.ListCitingArticles(id, number, subNumber, sort, page)
+		if err != nil {
+			if errors.Is(err, db.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询引用失败"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "成功", "data": result})
+
+	case citingComments:
+		if page.Cursor != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "评论引用列表不支持游标分页"})
+			return
+		}
+		list, total, err := db.ListCitingComments(id, number, subNumber, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询引用失败"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "成功",
+			"data": gin.H{
+				"list":      list,
+				"total":     total,
+				"page":      page.Page,
+				"page_size": page.PageSize,
+			},
+		})
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "source 应为 article 或 comment"})
+	}
+}
+
+// ImportStatute 导入法规的一个版本，施行日期相同的版本整体替换
+// 请求体为 JSON 或 Markdown 格式的法规（格式见 utils/statute），查询参数 format（json、markdown）未指定时按 Content-Type 判断
+func ImportStatute(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = "markdown"
+		if strings.Contains(c.ContentType(), "json") {
+			format = "json"
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxStatuteImportSize)
+	var law *statute.Law
+	var err error
+	switch format {
+	case "json":
+		law, err = statute.ParseJSON(body)
+	case "markdown":
+		law, err = statute.ParseMarkdown(body)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "format 应为 json 或 markdown"})
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "导入文件过大"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "导入文件格式不正确: " + err.Error()})
+		return
+	}
+
+	result, err := db.ImportStatute(law, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "导入法规失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "导入成功", "data": result})
+}
+
+// DeleteStatute 删除法规及其全部版本
+func DeleteStatute(c *gin.Context) {
+	id, ok := statuteIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteStatute(id); err != nil {
+		respondStatuteError(c, "删除法规失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功", "data": gin.H{"statute_id": id}})
+}
+
+// DeleteStatuteVersion 删除法规的一个版本
+func DeleteStatuteVersion(c *gin.Context) {
+	id, ok := statuteIDParam(c)
+	if !ok {
+		return
+	}
+	versionID, err := strconv.Atoi(c.Param("version_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的版本ID"})
+		return
+	}
+
+	if err := db.DeleteStatuteVersion(id, versionID); err != nil {
+		respondStatuteError(c, "删除版本失败", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功", "data": gin.H{"statute_id": id, "version_id": versionID}})
+}
+
+// statuteIDParam 解析路径参数中的法规ID，无效时写入错误响应
+func statuteIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的法规ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// statuteArticleParam 解析路径参数中的条号，无效时写入错误响应
+func statuteArticleParam(c *gin.Context) (int, int, bool) {
+	number, subNumber, ok := statute.ParseArticleRef(c.Param("number"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的条号"})
+		return 0, 0, false
+	}
+	return number, subNumber, true
+}
+
+// statuteDate 解析查询参数中的日期，默认今天，无效时写入错误响应
+func statuteDate(c *gin.Context) (string, bool) {
+	date := c.Query("date")
+	if date == "" {
+		return time.Now().Format(dateLayout), true
+	}
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "日期格式应为 2006-01-02"})
+		return "", false
+	}
+	return date, true
+}
+
+// respondStatuteError 将法规操作错误转换为响应
+func respondStatuteError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, db.ErrStatuteNotFound), errors.Is(err, db.ErrStatuteVersionNotFound),
+		errors.Is(err, db.ErrNoEffectiveVersion), errors.Is(err, db.ErrStatuteArticleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": message})
+	}
+}
+
+// linkCitations 将文章正文和评论树中的法条引用转换为链接，填充 linked_content，失败时写入错误响应；
+// 只链接现行版本中存在的条文
+func linkCitations(c *gin.Context, article *db.Article, comments []*db.Comment) bool {
+	index, err := db.GetStatuteIndex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询法规失败"})
+		return false
+	}
+
+	prefix := strings.TrimRight(config.GlobalConfig.Statute.LinkPrefix, "/")
+	link := func(content string) string {
+		return statute.Link(content, func(citation statute.Citation) (string, bool) {
+			id, ok := index.Lookup(citation)
+			return fmt.Sprintf("%s/%d/articles/%s", prefix, id, statute.ArticleRef(citation.Number, citation.SubNumber)), ok
+		})
+	}
+
+	if article != nil {
+		article.LinkedContent = link(article.Content)
+	}
+	var walk func(comments []*db.Comment)
+	walk = func(comments []*db.Comment) {
+		for _, comment := range comments {
+			comment.LinkedContent = link(comment.Content)
+			walk(comment.Replies)
+		}
+	}
+	walk(comments)
+	return true
+}
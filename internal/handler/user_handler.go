@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileRequest 修改个人资料的请求结构
+type ProfileRequest struct {
+	DisplayName string `json:"display_name" binding:"max=50"`
+	Bio         string `json:"bio" binding:"max=500"`
+	AvatarURL   string `json:"avatar_url" binding:"omitempty,url,max=512"`
+	RegionCode  string `json:"region_code"`
+	Title       string `json:"title" binding:"max=50"`
+}
+
+// ChangePasswordRequest 修改密码的请求结构
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// GetUserProfile 获取当前用户的资料、账号信息和统计数据
+func GetUserProfile(c *gin.Context) {
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return
+	}
+
+	profile, err := db.GetProfile(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询资料失败"})
+		return
+	}
+	stats, err := db.GetUserStats(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询资料失败"})
+		return
+	}
+	permissions, err := db.GetUserPermissions(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询资料失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"user":        user,
+			"profile":     profile,
+			"stats":       stats,
+			"permissions": permissions,
+		},
+	})
+}
+
+// UpdateUserProfile 修改当前用户的资料
+func UpdateUserProfile(c *gin.Context) {
+	var req ProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+	if !checkRegion(c, req.RegionCode) {
+		return
+	}
+
+	// 资料不经过人工审核，命中需审核的敏感词时直接拒绝
+	checked, ok := checkContent(c, &req.DisplayName, &req.Bio, &req.Title)
+	if !ok {
+		return
+	}
+	if checked.NeedsReview() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "资料包含敏感内容，请修改后提交"})
+		return
+	}
+
+	profile := &db.Profile{
+		UserID:      c.GetInt("user_id"),
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarURL:   req.AvatarURL,
+		RegionCode:  req.RegionCode,
+		Title:       req.Title,
+	}
+	if err := db.SaveProfile(profile); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "保存资料失败"})
+		return
+	}
+
+	saved, err := db.GetProfile(profile.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询资料失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "保存成功",
+		"data":    saved,
+	})
+}
+
+// ChangePassword 修改密码，需要提供当前密码，修改后所有设备需要重新登录
+func ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求格式不正确"})
+		return
+	}
+
+	value, _ := c.Get("user")
+	user, ok := value.(*db.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "需要认证"})
+		return
+	}
+	if !user.CheckPassword(req.OldPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "当前密码不正确"})
+		return
+	}
+
+	if err := db.UpdatePassword(user.ID, req.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "修改密码失败"})
+		return
+	}
+	// 使所有已登录的会话失效
+	if err := db.InvalidateUserTokens(user.ID, auth.Tokens.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "修改密码失败"})
+		return
+	}
+	if err := db.RevokeUserRefreshTokens(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "修改密码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "密码已修改，请重新登录"})
+}
+
+// GetPublicProfile 获取用户的公开资料、统计数据和发布的文章
+func GetPublicProfile(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的用户ID"})
+		return
+	}
+	page, ok := bindCursorPagination(c)
+	if !ok {
+		return
+	}
+
+	profile, err := db.GetProfile(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "用户不存在"})
+		return
+	}
+	stats, err := db.GetUserStats(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询用户资料失败"})
+		return
+	}
+	articles, err := db.ListArticlesByUser(userID, db.SortByLatest, page)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询用户文章失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成功",
+		"data": gin.H{
+			"profile":  profile,
+			"stats":    stats,
+			"articles": articles,
+		},
+	})
+}
@@ -1,25 +1,14 @@
 package middleware
 
 import (
-	"errors"
 	"net/http"
 	"strings"
-	"time"
 
+	"github.com/VanVodkaer/LawConnect-API/internal/auth"
 	"github.com/VanVodkaer/LawConnect-API/internal/db"
-	"github.com/VanVodkaer/LawConnect-API/utils/config"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-// 自定义JWT声明结构
-type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     int    `json:"role"`
-	jwt.RegisteredClaims
-}
-
 // JWTAuth JWT认证中间件
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -45,41 +34,31 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 解析令牌
-		tokenString := parts[1]
-		claims := &Claims{}
-
-		// 使用密钥解析令牌
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// 确保令牌使用了预期的签名方法
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("无效的签名方法")
-			}
-			return []byte(config.GlobalConfig.JWT.Secret), nil
-		})
-
-		// 处理解析错误
+		// 解析令牌：校验签名、有效期、签发者和受众
+		claims, err := auth.Tokens.ParseAccessToken(parts[1])
 		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"code":    401,
-					"message": "认证令牌已过期",
-				})
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"code":    401,
-					"message": "无效的认证令牌",
-				})
-			}
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": err.Error(),
+			})
 			c.Abort()
 			return
 		}
 
-		// 验证令牌是否有效
-		if !token.Valid {
+		// 检查令牌是否已被吊销（退出登录）
+		revoked, err := auth.Revocations.IsRevoked(claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "服务器内部错误",
+			})
+			c.Abort()
+			return
+		}
+		if revoked {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "认证令牌已过期或无效",
+				"message": "认证令牌已失效，请重新登录",
 			})
 			c.Abort()
 			return
@@ -96,11 +75,65 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		// 封禁立即生效，不等待令牌过期
+		if user.IsBanned(auth.Tokens.Now()) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": user.BanMessage(),
+			})
+			c.Abort()
+			return
+		}
+
+		// 退出所有设备之前签发的令牌一律失效
+		if user.TokensValidAfter != nil && claims.IssuedAt != nil && !claims.IssuedAt.After(*user.TokensValidAfter) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "认证令牌已失效，请重新登录",
+			})
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存储在上下文中以便后续处理
 		c.Set("user", user)
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("role", user.Role) // 使用最新角色，角色变更立即生效
+		c.Set("token_id", claims.ID)
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
+
+		c.Next()
+	}
+}
+
+// ReadOnlyUntilVerified 未验证邮箱的用户只能进行只读请求，allowed 中的路由除外
+func ReadOnlyUntilVerified(allowed ...string) gin.HandlerFunc {
+	allow := make(map[string]bool, len(allowed))
+	for _, path := range allowed {
+		allow[path] = true
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if allow[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		value, _ := c.Get("user")
+		if u, ok := value.(*db.User); ok && !u.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "请先验证邮箱",
+			})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -131,62 +164,76 @@ func AdminRequired() gin.HandlerFunc {
 			return
 		}
 
+		if !adminMFASatisfied(c, u) {
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
 
-// RefreshToken 刷新令牌
-func RefreshToken(c *gin.Context) {
-	// 从上下文中获取用户
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "需要认证",
-		})
-		return
-	}
+// RequirePermission 验证用户是否拥有指定权限的中间件，管理员拥有全部权限
+func RequirePermission(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 从上下文中获取用户
+		value, _ := c.Get("user")
+		u, ok := value.(*db.User)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "需要认证",
+			})
+			c.Abort()
+			return
+		}
 
-	u, ok := user.(*db.User)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "服务器内部错误",
-		})
-		return
-	}
+		// 检查用户的角色是否包含该权限
+		has, err := db.HasPermission(u, code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "服务器内部错误",
+			})
+			c.Abort()
+			return
+		}
+		if !has {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "没有权限执行该操作",
+			})
+			c.Abort()
+			return
+		}
 
-	// 生成新令牌
-	expireTime := time.Now().Add(time.Duration(config.GlobalConfig.JWT.Expire) * time.Hour)
-	claims := Claims{
-		UserID:   u.ID,
-		Username: u.Username,
-		Role:     u.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expireTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   u.Username,
-		},
+		// 管理员凭管理员身份通过时同样受两步验证策略约束
+		if u.IsAdmin() && !adminMFASatisfied(c, u) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
 	}
+}
 
-	// 创建JWT令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.GlobalConfig.JWT.Secret))
+// adminMFASatisfied 检查管理员是否满足两步验证策略，不满足时写入错误响应
+func adminMFASatisfied(c *gin.Context, u *db.User) bool {
+	// 策略要求管理员启用两步验证
+	required, err := db.GetBoolSetting(db.SettingRequireAdminMFA, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
-			"message": "生成令牌失败",
+			"message": "服务器内部错误",
 		})
-		return
+		return false
 	}
-
-	// 返回新令牌
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "刷新令牌成功",
-		"data": gin.H{
-			"token":  tokenString,
-			"expire": expireTime.Unix(),
-		},
-	})
+	if required && !u.TOTPEnabled {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "需要先启用两步验证",
+		})
+		return false
+	}
+	return true
 }
@@ -1,8 +1,14 @@
 package router
 
 import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/VanVodkaer/LawConnect-API/internal/db"
 	"github.com/VanVodkaer/LawConnect-API/internal/handler"
 	"github.com/VanVodkaer/LawConnect-API/internal/middleware"
+	"github.com/VanVodkaer/LawConnect-API/utils/config"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,11 +18,15 @@ type RouteGroups struct {
 	Auth   *gin.RouterGroup // 认证路由组
 	API    *gin.RouterGroup // API路由组
 	Admin  *gin.RouterGroup // 管理员路由组
+	Staff  *gin.RouterGroup // 后台管理路由组，按权限授权
 }
 
 // 全局变量，保存所有路由组的引用
 var Groups RouteGroups
 
+// sectionSlugPattern 栏目路径：以 / 分隔的若干段，不能包含路由参数
+var sectionSlugPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
 // InitGroups 初始化所有路由组
 func InitGroups(r *gin.Engine) {
 	// 初始化路由组
@@ -24,54 +34,182 @@ func InitGroups(r *gin.Engine) {
 	Groups.Auth = r.Group("/auth")
 	Groups.API = r.Group("/api")
 	Groups.API.Use(middleware.JWTAuth()) // API路由组需要JWT验证
+	// 未验证邮箱的用户只读，仍可退出登录和重新发送验证邮件
+	Groups.API.Use(middleware.ReadOnlyUntilVerified("/api/logout", "/api/logout-all", "/api/user/resend-verification"))
 	Groups.Admin = Groups.API.Group("/admin")
 	Groups.Admin.Use(middleware.AdminRequired()) // 管理员路由组需要管理员权限
+	// 与管理员路由组共用 /api/admin 前缀，每个路由通过 RequirePermission 单独授权，审核员等角色也可以访问
+	Groups.Staff = Groups.API.Group("/admin")
 }
 
 // RegisterRoutes 注册所有路由
 func RegisterRoutes(r *gin.Engine) {
+	// JWKS 公钥发布路由（标准路径，不属于任何路由组）
+	r.GET("/.well-known/jwks.json", handler.GetJWKS)
+
 	// 初始化路由组
 	InitGroups(r)
 	// 注册各组路由
 	registerPublicRoutes()
+	registerSectionRoutes(r.Routes())
 	registerAuthRoutes()
 	registerAPIRoutes()
 	registerAdminRoutes()
+	registerStaffRoutes()
 }
 
 // registerPublicRoutes 注册公共路由
 func registerPublicRoutes() {
-	// 法学交流社区
-	Groups.Public.GET("/community/latest", handler.GetCommunityLatest)
-	Groups.Public.GET("/community/hottest", handler.GetCommunityHottest)
-	Groups.Public.GET("/community/hotqa", handler.GetCommunityHotQA)
-	// 政策推送专区
-	Groups.Public.GET("/policy/latest", handler.GetPolicyLatest)
-	Groups.Public.GET("/policy/local", handler.GetPolicyLocal)
-	Groups.Public.GET("/policy/interpretation", handler.GetPolicyInterpretation)
-	// 线下实践平台
-	Groups.Public.GET("/offline/cooperation", handler.GetOfflineCooperation)
-	Groups.Public.GET("/offline/online", handler.GetOfflineOnline)
-	Groups.Public.GET("/offline/registration", handler.GetOfflineRegistration)
 	// 文章详情路由
 	Groups.Public.GET("/article/:id", handler.GetArticleDetail)
+	// 评论回复树路由
+	Groups.Public.GET("/comment/:id/replies", handler.GetCommentReplies)
+	// 全文搜索路由
+	Groups.Public.GET("/search", handler.Search)
+	// 分类树路由
+	Groups.Public.GET("/categories", handler.GetCategoryTree)
+	// 用户公开资料路由
+	Groups.Public.GET("/user/:id", handler.GetPublicProfile)
+	// 行政区划路由
+	Groups.Public.GET("/regions", handler.GetRegions)            // 下级区划，未指定上级时返回省级区划
+	Groups.Public.GET("/regions/:code", handler.GetRegionDetail) // 区划及其上级区划路径
+
+	// 政策路由
+	Groups.Public.GET("/policies", handler.ListPolicies)                   // 按字段筛选政策
+	Groups.Public.GET("/policies/:id", handler.GetPolicyDetail)            // 政策详情和解读文章
+	Groups.Public.GET("/article/:id/policies", handler.GetArticlePolicies) // 文章解读的政策
+
+	// 法规库路由
+	Groups.Public.GET("/statutes", handler.ListStatutes)                                              // 按名称查询法规
+	Groups.Public.GET("/statutes/:id", handler.GetStatuteDetail)                                      // 指定日期施行版本的章节和条文
+	Groups.Public.GET("/statutes/:id/articles/:number", handler.GetStatuteArticle)                    // 条文及其历史版本
+	Groups.Public.GET("/statutes/:id/articles/:number/citations", handler.GetStatuteArticleCitations) // 引用了该条的文章或评论
+
+	// 法律问答路由
+	Groups.Public.GET("/questions", handler.ListQuestions(db.QuestionSortLatest, 0)) // 问题列表
+	Groups.Public.GET("/questions/:id", handler.GetQuestionDetail)                   // 问题详情和回答
+}
+
+// registerSectionRoutes 根据配置中的栏目注册文章列表或问题列表路由（法学交流社区、政策推送专区、线下实践平台等），
+// 需在固定公共路由之后调用：栏目路径不能重复，第一段也不能与固定公共路由（如 article、search）相同
+func registerSectionRoutes(routes gin.RoutesInfo) {
+	reserved := make(map[string]bool)
+	for _, route := range routes {
+		if rest, ok := strings.CutPrefix(route.Path, Groups.Public.BasePath()+"/"); ok {
+			reserved[strings.SplitN(rest, "/", 2)[0]] = true
+		}
+	}
+	registered := make(map[string]config.Section)
+
+	for _, section := range config.GlobalConfig.Sections {
+		slug := strings.Trim(section.Slug, "/")
+		if !sectionSlugPattern.MatchString(slug) {
+			log.Fatalf("栏目路径不正确: %+v", section)
+		}
+		if reserved[strings.SplitN(slug, "/", 2)[0]] {
+			log.Fatalf("栏目路径与固定公共路由冲突: %+v", section)
+		}
+		if other, ok := registered[slug]; ok {
+			log.Fatalf("栏目路径重复: %+v 与 %+v", section, other)
+		}
+		registered[slug] = section
+		path := "/" + slug
+
+		switch section.Kind {
+		case "", config.SectionArticles:
+			sort := db.ArticleSort(section.Sort)
+			if len(section.CategoryIDs) == 0 || !sort.Valid() {
+				log.Fatalf("栏目配置不正确: %+v", section)
+			}
+			Groups.Public.GET(path, handler.ListSection(section.CategoryIDs, sort))
+		case config.SectionQuestions:
+			sort := db.QuestionSort(section.Sort)
+			if len(section.CategoryIDs) > 1 || !sort.Valid() {
+				log.Fatalf("栏目配置不正确: %+v", section)
+			}
+			categoryID := 0
+			if len(section.CategoryIDs) == 1 {
+				categoryID = section.CategoryIDs[0]
+			}
+			Groups.Public.GET(path, handler.ListQuestions(sort, categoryID))
+		default:
+			log.Fatalf("栏目类型不正确: %+v", section)
+		}
+	}
 }
 
 // registerAuthRoutes 注册认证相关路由
 func registerAuthRoutes() {
 	Groups.Auth.POST("/login", handler.Login)
 	Groups.Auth.POST("/register", handler.Register)
+	Groups.Auth.POST("/login/mfa", handler.LoginMFA) // 两步登录：提交验证码
+	Groups.Auth.POST("/refresh", handler.Refresh)    // 使用刷新令牌换取新令牌
+
+	// 邮箱验证和找回密码路由
+	Groups.Auth.POST("/verify-email", handler.VerifyEmail)       // 验证邮箱
+	Groups.Auth.POST("/forgot-password", handler.ForgotPassword) // 发送重置密码邮件
+	Groups.Auth.POST("/reset-password", handler.ResetPassword)   // 重置密码
 }
 
 // registerAPIRoutes 注册需要认证的API路由
 func registerAPIRoutes() {
-	// 示例路由，取消注释即可启用
-	// Groups.API.GET("/user/profile", handler.GetUserProfile)
-	// 刷新令牌路由
-	Groups.API.POST("/refresh-token", middleware.RefreshToken)
+	// 个人资料路由
+	Groups.API.GET("/user/profile", handler.GetUserProfile)    // 获取个人资料
+	Groups.API.PUT("/user/profile", handler.UpdateUserProfile) // 修改个人资料
+	Groups.API.PUT("/user/password", handler.ChangePassword)   // 修改密码
+
+	// 退出登录路由
+	Groups.API.POST("/logout", handler.Logout)        // 退出当前登录
+	Groups.API.POST("/logout-all", handler.LogoutAll) // 退出所有设备
+
+	// 邮箱验证路由
+	Groups.API.POST("/user/resend-verification", handler.ResendVerification) // 重新发送验证邮件
+
+	// 登录记录路由
+	Groups.API.GET("/user/login-history", handler.GetLoginHistory) // 当前用户的登录记录
+
+	// 律师认证路由
+	Groups.API.GET("/user/lawyer-verification", handler.GetMyLawyerVerification)  // 最近一次认证申请
+	Groups.API.POST("/user/lawyer-verification", handler.ApplyLawyerVerification) // 提交认证申请
+
+	// 两步验证路由
+	Groups.API.GET("/user/2fa", handler.GetMFAStatus)                            // 两步验证状态
+	Groups.API.POST("/user/2fa/setup", handler.SetupMFA)                         // 生成密钥和二维码
+	Groups.API.POST("/user/2fa/enable", handler.EnableMFA)                       // 确认绑定并启用
+	Groups.API.POST("/user/2fa/disable", handler.DisableMFA)                     // 关闭两步验证
+	Groups.API.POST("/user/2fa/recovery-codes", handler.RegenerateRecoveryCodes) // 重新生成恢复码
+
+	// 文章发布与管理路由
+	Groups.API.POST("/article", middleware.RequirePermission(db.PermArticlePublish), handler.CreateArticle) // 发布文章
+	Groups.API.PUT("/article/:id", handler.UpdateArticle)                                                   // 编辑文章
+	Groups.API.DELETE("/article/:id", handler.DeleteArticle)                                                // 删除文章
+
+	// 政策发布与解读关联路由
+	Groups.API.GET("/policy/nearby", handler.GetNearbyPolicies) // 身边的政策（按资料中的地区）
+	policy := middleware.RequirePermission(db.PermPolicyEdit)
+	Groups.API.POST("/policies", policy, handler.CreatePolicy)                                         // 发布政策
+	Groups.API.PUT("/policies/:id", policy, handler.UpdatePolicyMeta)                                  // 修改政策信息
+	Groups.API.POST("/policies/:id/interpretations", handler.AddPolicyInterpretation)                  // 关联解读文章
+	Groups.API.DELETE("/policies/:id/interpretations/:article_id", handler.RemovePolicyInterpretation) // 取消关联
+
+	// 法律问答路由（回答即问题下的顶层评论，投票即评论点赞）
+	Groups.API.POST("/questions", middleware.RequirePermission(db.PermArticlePublish), handler.CreateQuestion) // 提问
+	Groups.API.POST("/questions/:id/answer", handler.AddComment)                                               // 回答问题
+	Groups.API.POST("/questions/:id/accept", handler.AcceptAnswer)                                             // 采纳回答
+	Groups.API.POST("/answer/:id/vote", handler.LikeComment)                                                   // 为回答投票
+	Groups.API.DELETE("/answer/:id/vote", handler.UnlikeComment)                                               // 取消投票
+	Groups.API.GET("/user/points", handler.GetUserPoints)                                                      // 积分余额和记录
 
 	// 评论相关路由
 	Groups.API.POST("/article/:id/comment", handler.AddComment)
+	Groups.API.POST("/comment/:id/reply", handler.AddReply)           // 回复评论
+	Groups.API.PUT("/comment/:id", handler.UpdateComment)             // 编辑评论
+	Groups.API.DELETE("/comment/:id", handler.DeleteComment)          // 删除评论
+	Groups.API.GET("/comment/:id/history", handler.GetCommentHistory) // 编辑历史
+
+	// 通知相关路由
+	Groups.API.GET("/notifications", handler.GetNotifications)            // 获取通知
+	Groups.API.POST("/notifications/read", handler.MarkNotificationsRead) // 全部标为已读
 
 	// 文章点赞相关路由
 	Groups.API.POST("/article/:id/like", handler.LikeArticle)         // 点赞
@@ -87,6 +225,69 @@ func registerAPIRoutes() {
 
 // registerAdminRoutes 注册管理员路由
 func registerAdminRoutes() {
-	// 示例路由，取消注释即可启用
-	// Groups.Admin.GET("/users", handler.GetAllUsers)
+	// 用户管理路由
+	Groups.Admin.GET("/users", handler.GetUsers)                               // 搜索和分页查询用户
+	Groups.Admin.GET("/users/:id", handler.GetUserDetail)                      // 用户详情
+	Groups.Admin.PUT("/users/:id/role", handler.UpdateUserRole)                // 修改角色
+	Groups.Admin.POST("/users/:id/ban", handler.BanUser)                       // 封禁或暂停
+	Groups.Admin.DELETE("/users/:id/ban", handler.UnbanUser)                   // 解除封禁
+	Groups.Admin.POST("/users/:id/password-reset", handler.ForcePasswordReset) // 要求重置密码
+	Groups.Admin.DELETE("/users/:id", handler.DeleteUser)                      // 删除用户
+
+	// 安全策略路由
+	Groups.Admin.GET("/security/mfa-policy", handler.GetMFAPolicy)    // 两步验证策略
+	Groups.Admin.PUT("/security/mfa-policy", handler.UpdateMFAPolicy) // 修改两步验证策略
+
+	// 登录审计路由
+	Groups.Admin.GET("/login-attempts", handler.GetLoginAttempts) // 登录审计记录
+	Groups.Admin.POST("/login-unlock", handler.UnlockLogin)       // 解除登录锁定
+
+	// 角色与权限路由
+	Groups.Admin.GET("/permissions", handler.GetPermissions)   // 权限列表
+	Groups.Admin.GET("/roles", handler.GetRoles)               // 角色列表
+	Groups.Admin.POST("/roles", handler.CreateRole)            // 创建角色
+	Groups.Admin.PUT("/roles/:id", handler.UpdateRole)         // 修改角色权限
+	Groups.Admin.DELETE("/roles/:id", handler.DeleteRole)      // 删除角色
+	Groups.Admin.GET("/users/:id/roles", handler.GetUserRoles) // 用户的角色和权限
+	Groups.Admin.PUT("/users/:id/roles", handler.SetUserRoles) // 设置用户的额外角色
+}
+
+// registerStaffRoutes 注册按权限授权的后台管理路由
+func registerStaffRoutes() {
+	// 分类管理路由
+	category := middleware.RequirePermission(db.PermCategoryManage)
+	Groups.Staff.POST("/category", category, handler.CreateCategory)                      // 创建分类
+	Groups.Staff.PUT("/category/:id", category, handler.RenameCategory)                   // 重命名分类
+	Groups.Staff.PUT("/category/:id/parent", category, handler.MoveCategory)              // 移动分类
+	Groups.Staff.PUT("/category/:id/permission", category, handler.SetCategoryPermission) // 设置发布权限
+	Groups.Staff.DELETE("/category/:id", category, handler.DeleteCategory)                // 删除分类
+
+	// 评论审核路由
+	comment := middleware.RequirePermission(db.PermCommentModerate)
+	Groups.Staff.GET("/comments/pending", comment, handler.GetPendingComments) // 待审核评论
+	Groups.Staff.POST("/comments/approve", comment, handler.ApproveComments)   // 批量通过
+	Groups.Staff.POST("/comments/reject", comment, handler.RejectComments)     // 批量驳回
+
+	// 文章审核路由
+	Groups.Staff.PUT("/article/:id/visibility", middleware.RequirePermission(db.PermArticleModerate), handler.SetArticleVisibility) // 通过审核或下架
+
+	// 敏感词管理路由
+	filter := middleware.RequirePermission(db.PermFilterManage)
+	Groups.Staff.GET("/sensitive-words", filter, handler.GetSensitiveWords)            // 敏感词列表
+	Groups.Staff.POST("/sensitive-words", filter, handler.SaveSensitiveWord)           // 添加或更新敏感词
+	Groups.Staff.DELETE("/sensitive-words/:id", filter, handler.DeleteSensitiveWord)   // 删除敏感词
+	Groups.Staff.POST("/sensitive-words/reload", filter, handler.ReloadSensitiveWords) // 重新加载词库
+
+	// 律师认证审核路由
+	lawyer := middleware.RequirePermission(db.PermLawyerVerify)
+	Groups.Staff.GET("/lawyer-verifications", lawyer, handler.GetLawyerVerifications)                 // 审核队列
+	Groups.Staff.GET("/lawyer-verifications/:id", lawyer, handler.GetLawyerVerificationDetail)        // 申请详情
+	Groups.Staff.POST("/lawyer-verifications/:id/approve", lawyer, handler.ApproveLawyerVerification) // 通过
+	Groups.Staff.POST("/lawyer-verifications/:id/reject", lawyer, handler.RejectLawyerVerification)   // 驳回
+
+	// 法规库管理路由
+	statute := middleware.RequirePermission(db.PermStatuteManage)
+	Groups.Staff.POST("/statutes/import", statute, handler.ImportStatute)                            // 导入法规（JSON 或 Markdown）
+	Groups.Staff.DELETE("/statutes/:id", statute, handler.DeleteStatute)                             // 删除法规
+	Groups.Staff.DELETE("/statutes/:id/versions/:version_id", statute, handler.DeleteStatuteVersion) // 删除版本
 }
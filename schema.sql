@@ -7,10 +7,21 @@ USE LawConnectDB;
 -- 创建用户表
 CREATE TABLE IF NOT EXISTS users (
     id INT AUTO_INCREMENT PRIMARY KEY COMMENT '用户ID',
-    username VARCHAR(50) NOT NULL COMMENT '用户名',
+    username VARCHAR(50) NOT NULL UNIQUE COMMENT '用户名，必须唯一',
     email VARCHAR(100) NOT NULL UNIQUE COMMENT '邮箱，必须唯一',
     password VARCHAR(255) NOT NULL COMMENT '密码',
-    role TINYINT NOT NULL DEFAULT 1 COMMENT '用户权限：1-普通用户，2-管理员'
+    role TINYINT NOT NULL DEFAULT 1 COMMENT '用户权限：1-普通用户，2-管理员',
+    email_verified TINYINT NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证：0-未验证（只读），1-已验证',
+    tokens_valid_after TIMESTAMP(3) NULL DEFAULT NULL COMMENT '此时间及之前签发的令牌全部失效（退出所有设备），精确到毫秒，由应用写入',
+    totp_secret VARCHAR(64) DEFAULT NULL COMMENT 'TOTP 密钥（base32），开始绑定后生成',
+    totp_enabled TINYINT NOT NULL DEFAULT 0 COMMENT '是否启用两步验证：0-未启用，1-已启用',
+    totp_last_step BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次验证通过的时间步，防止验证码重放',
+    must_reset_password TINYINT NOT NULL DEFAULT 0 COMMENT '管理员要求重置密码：0-否，1-是（重置前不能登录）',
+    banned_at TIMESTAMP NULL DEFAULT NULL COMMENT '封禁时间，为空表示未封禁',
+    banned_until TIMESTAMP NULL DEFAULT NULL COMMENT '封禁截止时间，为空表示永久封禁',
+    ban_reason VARCHAR(255) NOT NULL DEFAULT '' COMMENT '封禁原因',
+    points INT NOT NULL DEFAULT 100 COMMENT '积分，用于问答悬赏，注册时赠送 100',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '注册时间'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- 创建分类表，支持父分类
@@ -18,6 +29,7 @@ CREATE TABLE IF NOT EXISTS categories (
     id INT AUTO_INCREMENT PRIMARY KEY COMMENT '分类ID',
     name VARCHAR(100) NOT NULL COMMENT '分类名称',
     parent_id INT DEFAULT NULL COMMENT '父分类ID',
+    publish_permission VARCHAR(50) DEFAULT NULL COMMENT '在该分类及其子分类下发布文章所需的权限，为空时继承父分类',
     CONSTRAINT fk_parent_category FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
@@ -27,14 +39,19 @@ CREATE TABLE IF NOT EXISTS articles (
     is_visible TINYINT NOT NULL DEFAULT 1 COMMENT '是否可见：0-不可见，1-可见',
     title VARCHAR(255) NOT NULL COMMENT '文章标题',
     content TEXT NOT NULL COMMENT '文章内容',
+    cover_url VARCHAR(512) NOT NULL DEFAULT '' COMMENT '封面图片地址，为空时取正文第一张图片',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后编辑时间',
     likes INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '点赞数',
     comment_count INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '评论数量',
     user_id INT NOT NULL COMMENT '发布用户ID',
     category_id INT NOT NULL COMMENT '文章分类ID',
+    region_code VARCHAR(12) NOT NULL DEFAULT '' COMMENT '适用地区的行政区划代码（地方政策、线下活动等），为空表示不限地区',
     INDEX idx_user_id (user_id),
     INDEX idx_category_id (category_id),
+    INDEX idx_region_code (region_code),
+    FULLTEXT INDEX ft_title (title) WITH PARSER ngram, -- 标题全文索引，用于提升标题命中的权重
+    FULLTEXT INDEX ft_title_content (title, content) WITH PARSER ngram, -- 标题和正文全文索引，ngram 分词支持中文
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -46,11 +63,21 @@ CREATE TABLE IF NOT EXISTS comments (
     content TEXT NOT NULL COMMENT '评论内容',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '评论发表时间',
     user_id INT NOT NULL COMMENT '发表评论的用户ID',
-    is_visible TINYINT NOT NULL DEFAULT 0 COMMENT '是否可见：0-审核中，1-可见，2-审核未通过',
+    is_visible TINYINT NOT NULL DEFAULT 0 COMMENT '是否可见：0-审核中，1-可见，2-审核未通过，3-已删除',
     likes INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '点赞数',
+    parent_id INT DEFAULT NULL COMMENT '回复的父评论ID，为空表示顶层评论',
+    review_reason VARCHAR(255) NOT NULL DEFAULT '' COMMENT '审核意见',
+    reviewed_by INT DEFAULT NULL COMMENT '审核管理员ID',
+    reviewed_at TIMESTAMP NULL DEFAULT NULL COMMENT '审核时间',
+    edited_at TIMESTAMP NULL DEFAULT NULL COMMENT '最后编辑时间',
+    deleted_at TIMESTAMP NULL DEFAULT NULL COMMENT '删除时间',
     INDEX idx_article_id (article_id),
     INDEX idx_user_id (user_id),
+    INDEX idx_parent_id (parent_id),
+    INDEX idx_visible_created (is_visible, created_at), -- 用于查询待审核评论
+    FULLTEXT INDEX ft_content (content) WITH PARSER ngram, -- 评论全文索引，ngram 分词支持中文
     FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
@@ -76,4 +103,347 @@ CREATE TABLE IF NOT EXISTS comment_likes (
     UNIQUE KEY uk_comment_user (comment_id, user_id), -- 确保一个用户只能给同一评论点赞一次
     FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建通知表（审核结果等系统消息）
+CREATE TABLE IF NOT EXISTS notifications (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '通知ID',
+    user_id INT NOT NULL COMMENT '接收通知的用户ID',
+    type VARCHAR(50) NOT NULL COMMENT '通知类型',
+    content VARCHAR(1000) NOT NULL COMMENT '通知内容',
+    is_read TINYINT NOT NULL DEFAULT 0 COMMENT '是否已读：0-未读，1-已读',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '通知时间',
+    INDEX idx_user_read (user_id, is_read),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建敏感词表（管理员维护，修改后实时生效）
+CREATE TABLE IF NOT EXISTS sensitive_words (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '敏感词ID',
+    word VARCHAR(100) NOT NULL COMMENT '敏感词',
+    action VARCHAR(20) NOT NULL DEFAULT 'mask' COMMENT '处理方式：block-拒绝，mask-打码，review-转人工审核',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '添加时间',
+    UNIQUE KEY uk_word (word)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建评论编辑历史表（保存每次编辑前的内容）
+CREATE TABLE IF NOT EXISTS comment_edits (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '编辑记录ID',
+    comment_id INT NOT NULL COMMENT '被编辑的评论ID',
+    content TEXT NOT NULL COMMENT '编辑前的评论内容',
+    edited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '编辑时间',
+    INDEX idx_comment_id (comment_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建刷新令牌表（只保存令牌哈希，同一次登录轮换出的令牌属于同一家族）
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '刷新令牌ID',
+    user_id INT NOT NULL COMMENT '令牌所属用户ID',
+    family_id CHAR(32) NOT NULL COMMENT '令牌家族ID，同一次登录轮换出的令牌共享',
+    token_hash CHAR(64) NOT NULL COMMENT '令牌的 SHA-256 哈希',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '签发时间',
+    used_at TIMESTAMP NULL DEFAULT NULL COMMENT '已轮换（使用）时间',
+    revoked_at TIMESTAMP NULL DEFAULT NULL COMMENT '吊销时间',
+    UNIQUE KEY uk_token_hash (token_hash),
+    INDEX idx_family_id (family_id),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建已吊销令牌表（记录已退出登录的访问令牌，过期后清理）
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti CHAR(32) PRIMARY KEY COMMENT '令牌ID',
+    expires_at TIMESTAMP NOT NULL COMMENT '令牌原过期时间，过期后可清理',
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建两步验证恢复码表（只保存哈希，每个恢复码只能使用一次）
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '恢复码ID',
+    user_id INT NOT NULL COMMENT '恢复码所属用户ID',
+    code_hash CHAR(64) NOT NULL COMMENT '恢复码的 SHA-256 哈希',
+    used_at TIMESTAMP NULL DEFAULT NULL COMMENT '使用时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '生成时间',
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建系统设置表（管理员在运行时修改的开关）
+CREATE TABLE IF NOT EXISTS settings (
+    name VARCHAR(100) PRIMARY KEY COMMENT '设置项名称',
+    value VARCHAR(255) NOT NULL COMMENT '设置项的值',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建登录审计表（记录每一次登录尝试）
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '记录ID',
+    user_id INT DEFAULT NULL COMMENT '用户ID，账号不存在时为空',
+    account VARCHAR(100) NOT NULL COMMENT '登录时填写的用户名或邮箱',
+    ip VARCHAR(45) NOT NULL COMMENT '来源IP',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '' COMMENT '客户端标识',
+    success TINYINT NOT NULL DEFAULT 0 COMMENT '是否成功：0-失败，1-成功',
+    reason VARCHAR(50) NOT NULL COMMENT '结果原因：success、bad_password、unknown_account、locked、mfa_required、bad_mfa_code、banned、reset_required',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '尝试时间',
+    INDEX idx_user_id (user_id),
+    INDEX idx_account (account),
+    INDEX idx_ip (ip),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建登录锁定表（按账号和按IP分别记录连续失败次数，锁定时长随失败次数指数增长）
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    scope VARCHAR(20) NOT NULL COMMENT '计数范围：account-账号，ip-来源IP',
+    lock_key VARCHAR(120) NOT NULL COMMENT '账号标识或IP',
+    failures INT NOT NULL DEFAULT 0 COMMENT '连续失败次数',
+    locked_until TIMESTAMP NULL DEFAULT NULL COMMENT '锁定截止时间',
+    last_failed_at TIMESTAMP NOT NULL COMMENT '最近一次失败时间',
+    PRIMARY KEY (scope, lock_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建邮件令牌表（邮箱验证和重置密码链接，每个令牌只能使用一次）
+CREATE TABLE IF NOT EXISTS email_tokens (
+    jti CHAR(32) PRIMARY KEY COMMENT '令牌ID',
+    user_id INT NOT NULL COMMENT '令牌所属用户ID',
+    purpose VARCHAR(20) NOT NULL COMMENT '令牌用途：verify_email-邮箱验证，reset_password-重置密码',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    used_at TIMESTAMP NULL DEFAULT NULL COMMENT '使用时间，重新发送后旧令牌也会被标记为已使用',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '签发时间',
+    INDEX idx_user_purpose (user_id, purpose),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建用户资料表（展示给其他用户的公开资料）
+CREATE TABLE IF NOT EXISTS user_profiles (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    display_name VARCHAR(50) NOT NULL DEFAULT '' COMMENT '昵称，为空时显示用户名',
+    bio VARCHAR(500) NOT NULL DEFAULT '' COMMENT '个人简介',
+    avatar_url VARCHAR(512) NOT NULL DEFAULT '' COMMENT '头像地址',
+    region_code VARCHAR(12) NOT NULL DEFAULT '' COMMENT '所在地区的行政区划代码',
+    title VARCHAR(50) NOT NULL DEFAULT '' COMMENT '职业头衔，如律师、法学教师',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建角色表（role 1、2 与 users.role 的基础角色对应，其余角色可额外授予用户）
+CREATE TABLE IF NOT EXISTS roles (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '角色ID',
+    name VARCHAR(50) NOT NULL UNIQUE COMMENT '角色名称',
+    description VARCHAR(255) NOT NULL DEFAULT '' COMMENT '角色说明',
+    is_system TINYINT NOT NULL DEFAULT 0 COMMENT '是否为系统角色：0-否，1-是（不能删除，也不能手动授予）'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建权限表
+CREATE TABLE IF NOT EXISTS permissions (
+    code VARCHAR(50) PRIMARY KEY COMMENT '权限代码',
+    description VARCHAR(255) NOT NULL DEFAULT '' COMMENT '权限说明'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建角色权限表
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT NOT NULL COMMENT '角色ID',
+    permission VARCHAR(50) NOT NULL COMMENT '权限代码',
+    PRIMARY KEY (role_id, permission),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission) REFERENCES permissions(code) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建用户角色表（在基础角色之外额外授予的角色）
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INT NOT NULL COMMENT '用户ID',
+    role_id INT NOT NULL COMMENT '角色ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '授予时间',
+    PRIMARY KEY (user_id, role_id),
+    INDEX idx_role_id (role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 初始化权限
+INSERT IGNORE INTO permissions (code, description) VALUES
+    ('article.publish', '发布文章'),
+    ('article.moderate', '编辑、删除他人文章，审核文章'),
+    ('comment.moderate', '删除他人评论，审核评论'),
+    ('category.manage', '管理分类'),
+    ('filter.manage', '管理敏感词'),
+    ('policy.edit', '发布和编辑政策'),
+    ('event.manage', '发布和管理线下活动'),
+    ('lawyer.verify', '审核律师认证'),
+    ('statute.manage', '导入和管理法律法规');
+
+-- 初始化角色（管理员拥有全部权限，无需配置）
+INSERT IGNORE INTO roles (id, name, description, is_system) VALUES
+    (1, 'user', '普通用户', 1),
+    (2, 'admin', '管理员，拥有全部权限', 1),
+    (3, 'moderator', '内容审核员', 0),
+    (4, 'policy_editor', '政策编辑', 0),
+    (5, 'event_organizer', '活动组织者', 0),
+    (6, 'lawyer', '认证律师，律师认证通过后自动授予', 1);
+
+INSERT IGNORE INTO role_permissions (role_id, permission) VALUES
+    (1, 'article.publish'),
+    (3, 'article.moderate'),
+    (3, 'comment.moderate'),
+    (4, 'policy.edit'),
+    (4, 'statute.manage'),
+    (5, 'event.manage');
+
+-- 创建律师认证申请表
+CREATE TABLE IF NOT EXISTS lawyer_verifications (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '申请ID',
+    user_id INT NOT NULL COMMENT '申请人用户ID',
+    license_number VARCHAR(50) NOT NULL COMMENT '律师执业证号',
+    law_firm VARCHAR(100) NOT NULL COMMENT '执业机构（律师事务所）',
+    jurisdiction VARCHAR(100) NOT NULL COMMENT '执业地区',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '状态：pending-待审核，approved-已通过，rejected-已驳回，expired-已过期',
+    review_note VARCHAR(255) NOT NULL DEFAULT '' COMMENT '审核意见',
+    reviewed_by INT DEFAULT NULL COMMENT '审核人用户ID',
+    reviewed_at TIMESTAMP NULL DEFAULT NULL COMMENT '审核时间',
+    expires_at TIMESTAMP NULL DEFAULT NULL COMMENT '认证有效期，为空表示长期有效',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '申请时间',
+    INDEX idx_user_status (user_id, status),
+    INDEX idx_status (status, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建律师认证证明材料表（执业证、身份证明等文件的地址，仅申请人和审核人可见）
+CREATE TABLE IF NOT EXISTS lawyer_verification_documents (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '材料ID',
+    verification_id INT NOT NULL COMMENT '认证申请ID',
+    name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '材料名称',
+    url VARCHAR(512) NOT NULL COMMENT '材料文件地址',
+    FOREIGN KEY (verification_id) REFERENCES lawyer_verifications(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建问题表（问题正文、点赞复用文章，回答为问题下的顶层评论，回答的投票复用评论点赞）
+CREATE TABLE IF NOT EXISTS questions (
+    article_id INT PRIMARY KEY COMMENT '问题对应的文章ID',
+    bounty INT NOT NULL DEFAULT 0 COMMENT '悬赏积分，提问时从提问者积分中扣除，采纳回答后发放给回答者',
+    accepted_comment_id INT DEFAULT NULL COMMENT '被采纳的回答ID',
+    accepted_at TIMESTAMP NULL DEFAULT NULL COMMENT '采纳时间',
+    INDEX idx_bounty (bounty),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (accepted_comment_id) REFERENCES comments(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建积分变动记录表
+CREATE TABLE IF NOT EXISTS point_logs (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '记录ID',
+    user_id INT NOT NULL COMMENT '用户ID',
+    delta INT NOT NULL COMMENT '积分变动，正数为增加，负数为扣除',
+    balance INT NOT NULL COMMENT '变动后的积分余额',
+    reason VARCHAR(30) NOT NULL COMMENT '原因：bounty_hold-悬赏扣除，bounty_refund-悬赏退还，bounty_award-获得悬赏，answer_accepted-回答被采纳',
+    ref_id INT DEFAULT NULL COMMENT '关联的问题ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '变动时间',
+    INDEX idx_user_id (user_id, id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建政策文件表（政策正文复用文章，此表保存政策的结构化信息）
+CREATE TABLE IF NOT EXISTS policies (
+    article_id INT PRIMARY KEY COMMENT '政策对应的文章ID',
+    doc_number VARCHAR(100) NOT NULL DEFAULT '' COMMENT '发文字号，如 国发〔2024〕1号',
+    authority VARCHAR(100) NOT NULL COMMENT '发布机关',
+    publish_date DATE NOT NULL COMMENT '发布日期',
+    effective_date DATE DEFAULT NULL COMMENT '施行日期',
+    status VARCHAR(20) NOT NULL DEFAULT 'effective' COMMENT '效力状态：effective-现行有效，amended-已修改，repealed-已废止',
+    INDEX idx_doc_number (doc_number),
+    INDEX idx_authority (authority),
+    INDEX idx_publish_date (publish_date),
+    INDEX idx_effective_date (effective_date),
+    INDEX idx_status (status),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建政策解读关联表（政策与解读文章多对多）
+CREATE TABLE IF NOT EXISTS policy_interpretations (
+    policy_id INT NOT NULL COMMENT '政策ID',
+    article_id INT NOT NULL COMMENT '解读文章ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '关联时间',
+    PRIMARY KEY (policy_id, article_id),
+    INDEX idx_article_id (article_id),
+    FOREIGN KEY (policy_id) REFERENCES policies(article_id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建行政区划表（GB/T 2260，启动时从区划代码文件导入）
+CREATE TABLE IF NOT EXISTS regions (
+    code VARCHAR(12) PRIMARY KEY COMMENT '行政区划代码',
+    name VARCHAR(50) NOT NULL COMMENT '区划名称',
+    parent_code VARCHAR(12) DEFAULT NULL COMMENT '上级区划代码，省级区划为空',
+    level TINYINT NOT NULL COMMENT '级别：1-省级，2-地级，3-县级',
+    INDEX idx_parent_code (parent_code),
+    FOREIGN KEY (parent_code) REFERENCES regions(code) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建法律法规表
+CREATE TABLE IF NOT EXISTS statutes (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '法规ID',
+    title VARCHAR(200) NOT NULL COMMENT '全称，如 中华人民共和国民法典',
+    short_title VARCHAR(100) NOT NULL DEFAULT '' COMMENT '简称，如 民法典',
+    authority VARCHAR(100) NOT NULL DEFAULT '' COMMENT '制定机关',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '收录时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+    UNIQUE KEY uk_title (title)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建法规版本表（按施行日期区分，查询时取指定日期已施行的最新版本）
+CREATE TABLE IF NOT EXISTS statute_versions (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '版本ID',
+    statute_id INT NOT NULL COMMENT '法规ID',
+    effective_date DATE NOT NULL COMMENT '施行日期',
+    note VARCHAR(255) NOT NULL DEFAULT '' COMMENT '版本说明，如 根据某决定修正',
+    imported_by INT DEFAULT NULL COMMENT '导入人用户ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '导入时间',
+    UNIQUE KEY uk_statute_date (statute_id, effective_date),
+    FOREIGN KEY (statute_id) REFERENCES statutes(id) ON DELETE CASCADE,
+    FOREIGN KEY (imported_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建法规章节表（编、分编、章、节）
+CREATE TABLE IF NOT EXISTS statute_nodes (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '章节ID',
+    version_id INT NOT NULL COMMENT '版本ID',
+    parent_id INT DEFAULT NULL COMMENT '上级章节ID',
+    kind VARCHAR(20) NOT NULL DEFAULT '' COMMENT '类型：part-编，subpart-分编，chapter-章，section-节',
+    title VARCHAR(200) NOT NULL COMMENT '标题，如 第一章 基本规定',
+    sort_order INT NOT NULL DEFAULT 0 COMMENT '在版本中的顺序',
+    INDEX idx_version_id (version_id, sort_order),
+    FOREIGN KEY (version_id) REFERENCES statute_versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES statute_nodes(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建法规条文表
+CREATE TABLE IF NOT EXISTS statute_articles (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '条文ID',
+    version_id INT NOT NULL COMMENT '版本ID',
+    node_id INT DEFAULT NULL COMMENT '所属章节ID，为空表示不属于任何章节',
+    number INT NOT NULL COMMENT '条号',
+    sub_number INT NOT NULL DEFAULT 0 COMMENT '之几，如 第一百三十三条之一 为 1',
+    content TEXT NOT NULL COMMENT '条文内容',
+    sort_order INT NOT NULL DEFAULT 0 COMMENT '在版本中的顺序',
+    UNIQUE KEY uk_version_number (version_id, number, sub_number),
+    INDEX idx_node_id (node_id),
+    FOREIGN KEY (version_id) REFERENCES statute_versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES statute_nodes(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 创建法条引用表（文章或评论正文中引用的法规条文，保存时自动识别）
+CREATE TABLE IF NOT EXISTS statute_citations (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '引用ID',
+    statute_id INT NOT NULL COMMENT '被引用的法规ID',
+    number INT NOT NULL COMMENT '被引用的条号',
+    sub_number INT NOT NULL DEFAULT 0 COMMENT '被引用条文的之几',
+    article_id INT NOT NULL COMMENT '引用所在的文章ID（评论中的引用为评论所属的文章）',
+    comment_id INT DEFAULT NULL COMMENT '引用所在的评论ID，为空表示引用在文章正文中',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '识别时间',
+    INDEX idx_clause (statute_id, number, sub_number),
+    INDEX idx_article_id (article_id),
+    INDEX idx_comment_id (comment_id),
+    FOREIGN KEY (statute_id) REFERENCES statutes(id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
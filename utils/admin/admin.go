@@ -33,7 +33,7 @@ func CreateAdminIfNotExists() {
 	db.DB.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", username).Scan(&count)
 	if count > 0 {
 		// 如果有同名普通用户，将其升级为管理员
-		_, err := db.DB.Exec("UPDATE users SET role = ? WHERE username = ?", db.RoleAdmin, username)
+		_, err := db.DB.Exec("UPDATE users SET role = ?, email_verified = 1 WHERE username = ?", db.RoleAdmin, username)
 		if err != nil {
 			log.Printf("更新用户为管理员失败: %v", err)
 		} else {
@@ -50,7 +50,7 @@ func CreateAdminIfNotExists() {
 	}
 
 	// 创建管理员用户
-	_, err = db.DB.Exec("INSERT INTO users (username, email, password, role) VALUES (?, ?, ?, ?)",
+	_, err = db.DB.Exec("INSERT INTO users (username, email, password, role, email_verified) VALUES (?, ?, ?, ?, 1)",
 		username, email, string(hashedPassword), db.RoleAdmin)
 	if err != nil {
 		log.Printf("创建管理员失败: %v", err)
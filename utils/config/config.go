@@ -23,9 +23,100 @@ type Config struct {
 	} `yaml:"database"`
 
 	JWT struct {
-		Secret string `yaml:"secret"`
-		Expire int    `yaml:"expire"`
+		Issuer        string `yaml:"issuer"`   // 令牌签发者（iss）
+		Audience      string `yaml:"audience"` // 令牌受众（aud）
+		Secret        string `yaml:"secret"`
+		Expire        int    `yaml:"expire"`         // 访问令牌有效期（秒）
+		RefreshExpire int    `yaml:"refresh_expire"` // 刷新令牌有效期（秒）
+
+		Algorithm      string `yaml:"algorithm"`       // 签名算法：HS256、RS256、ES256、EdDSA
+		KeyDir         string `yaml:"key_dir"`         // 非对称签名私钥目录（多实例共享）
+		RotateInterval int    `yaml:"rotate_interval"` // 签名密钥轮换周期（小时），0 表示不轮换
+
+		RevocationStore string `yaml:"revocation_store"` // 已吊销令牌存储：memory 或 mysql
+		CleanupInterval int    `yaml:"cleanup_interval"` // 过期吊销记录清理间隔（秒）
 	} `yaml:"jwt"`
+
+	Login struct {
+		MaxAccountFailures int `yaml:"max_account_failures"` // 同一账号连续失败多少次后锁定，0 表示不锁定
+		MaxIPFailures      int `yaml:"max_ip_failures"`      // 同一IP连续失败多少次后锁定，0 表示不锁定
+		LockoutBase        int `yaml:"lockout_base"`         // 首次锁定时长（秒），此后每次失败翻倍
+		LockoutMax         int `yaml:"lockout_max"`          // 最长锁定时长（秒）
+		FailureWindow      int `yaml:"failure_window"`       // 距上次失败超过该时长（秒）后重新计数
+	} `yaml:"login"`
+
+	Mail struct {
+		Driver       string `yaml:"driver"` // 发送方式：smtp、file、memory
+		From         string `yaml:"from"`   // 发件人地址
+		Host         string `yaml:"host"`   // SMTP 服务器
+		Port         int    `yaml:"port"`   // SMTP 端口
+		Username     string `yaml:"username"`
+		Password     string `yaml:"password"`
+		Path         string `yaml:"path"`          // file 方式的输出文件，为空时写入日志
+		BaseURL      string `yaml:"base_url"`      // 前端地址，用于生成邮件中的链接
+		VerifyExpire int    `yaml:"verify_expire"` // 邮箱验证链接有效期（秒）
+		ResetExpire  int    `yaml:"reset_expire"`  // 重置密码链接有效期（秒）
+	} `yaml:"mail"`
+
+	Sections []Section `yaml:"sections"`
+
+	Moderation struct {
+		Mode            string `yaml:"mode"`             // 评论审核模式：auto、review、trusted
+		TrustedApproved int    `yaml:"trusted_approved"` // trusted 模式下免审所需的已通过评论数
+	} `yaml:"moderation"`
+
+	Filter struct {
+		ReloadInterval int `yaml:"reload_interval"` // 敏感词库定期重新加载间隔（秒），0 表示只在修改时重新加载
+	} `yaml:"filter"`
+
+	QA struct {
+		CategoryID   int `yaml:"category_id"`   // 未指定分类时问题所属的分类
+		MaxBounty    int `yaml:"max_bounty"`    // 单个问题的最高悬赏积分
+		AcceptReward int `yaml:"accept_reward"` // 回答被采纳时额外奖励的积分
+	} `yaml:"qa"`
+
+	Regions struct {
+		File string `yaml:"file"` // GB/T 2260 行政区划代码文件，启动时导入
+	} `yaml:"regions"`
+
+	Statute struct {
+		LinkPrefix string `yaml:"link_prefix"` // 法条引用链接的前缀，链接为 <前缀>/<法规ID>/articles/<条号>
+	} `yaml:"statute"`
+}
+
+// 评论审核模式
+const (
+	ModerationAuto    = "auto"    // 自动通过
+	ModerationReview  = "review"  // 全部先审后发
+	ModerationTrusted = "trusted" // 可信用户自动通过，其余先审后发
+)
+
+// 栏目类型
+const (
+	SectionArticles  = "articles"  // 文章列表
+	SectionQuestions = "questions" // 问题列表
+)
+
+// Section 栏目配置：将公共路由映射到一组分类和排序方式
+type Section struct {
+	Slug        string `yaml:"slug"`         // 路由路径，如 community/latest，注册为 /public/community/latest
+	Name        string `yaml:"name"`         // 栏目名称
+	Kind        string `yaml:"kind"`         // 栏目类型：articles（默认）、questions
+	CategoryIDs []int  `yaml:"category_ids"` // 包含的分类（含子分类），问题栏目最多一个，为空表示不限
+	Sort        string `yaml:"sort"`         // 排序方式：文章为 latest、likes、comments，问题为 latest、bounty、answers
+}
+
+// DefaultSections 配置文件未定义栏目时使用的默认栏目
+var DefaultSections = []Section{
+	{Slug: "community/latest", Name: "最新动态", CategoryIDs: []int{1}, Sort: "latest"},
+	{Slug: "community/hottest", Name: "最热帖子", CategoryIDs: []int{1}, Sort: "likes"},
+	{Slug: "community/hotqa", Name: "热门问答", Kind: SectionQuestions, Sort: "answers"},
+	{Slug: "policy/latest", Name: "最新政策", CategoryIDs: []int{2}, Sort: "latest"},
+	{Slug: "policy/local", Name: "地方政策", CategoryIDs: []int{4}, Sort: "latest"},
+	{Slug: "policy/interpretation", Name: "政策解读", CategoryIDs: []int{5}, Sort: "latest"},
+	{Slug: "offline/cooperation", Name: "线下联动", CategoryIDs: []int{6}, Sort: "latest"},
+	{Slug: "offline/online", Name: "线上活动", CategoryIDs: []int{7}, Sort: "latest"},
+	{Slug: "offline/registration", Name: "报名中心", CategoryIDs: []int{8}, Sort: "latest"},
 }
 
 // GlobalConfig 作为全局变量存储配置信息
@@ -38,10 +129,87 @@ func LoadConfig(filename string) {
 		log.Fatalf("无法读取配置文件: %v", err)
 	}
 
+	// 访问令牌有效期在解析前设置默认值（15 分钟），以区分未配置和显式配置为 0
+	GlobalConfig.JWT.Expire = 15 * 60
+
 	err = yaml.Unmarshal(data, &GlobalConfig)
 	if err != nil {
 		log.Fatalf("解析 YAML 配置失败: %v", err)
 	}
 
+	// 未配置签发者和受众时使用默认值
+	if GlobalConfig.JWT.Issuer == "" {
+		GlobalConfig.JWT.Issuer = "lawconnect"
+	}
+	if GlobalConfig.JWT.Audience == "" {
+		GlobalConfig.JWT.Audience = "lawconnect-api"
+	}
+
+	// 未配置密钥目录时使用 keys
+	if GlobalConfig.JWT.KeyDir == "" {
+		GlobalConfig.JWT.KeyDir = "keys"
+	}
+
+	// 访问令牌有效期不大于 0 时签发的令牌立即过期，拒绝启动
+	if GlobalConfig.JWT.Expire <= 0 {
+		log.Fatalf("jwt.expire 必须大于 0（单位为秒）: %d", GlobalConfig.JWT.Expire)
+	}
+	// jwt.expire 的单位已由小时改为秒，过小的值多半是沿用了旧配置
+	if GlobalConfig.JWT.Expire < 60 {
+		log.Printf("警告: jwt.expire 为 %d 秒，该配置的单位已由小时改为秒，请确认", GlobalConfig.JWT.Expire)
+	}
+
+	// 未配置刷新令牌有效期时默认 30 天
+	if GlobalConfig.JWT.RefreshExpire <= 0 {
+		GlobalConfig.JWT.RefreshExpire = 30 * 24 * 3600
+	}
+
+	// 未配置登录锁定时长时使用默认值
+	if GlobalConfig.Login.LockoutBase <= 0 {
+		GlobalConfig.Login.LockoutBase = 60
+	}
+	if GlobalConfig.Login.LockoutMax <= 0 {
+		GlobalConfig.Login.LockoutMax = 24 * 3600
+	}
+	if GlobalConfig.Login.FailureWindow <= 0 {
+		GlobalConfig.Login.FailureWindow = 24 * 3600
+	}
+
+	// 未配置邮件链接有效期时，邮箱验证 24 小时、重置密码 1 小时
+	if GlobalConfig.Mail.VerifyExpire <= 0 {
+		GlobalConfig.Mail.VerifyExpire = 24 * 3600
+	}
+	if GlobalConfig.Mail.ResetExpire <= 0 {
+		GlobalConfig.Mail.ResetExpire = 3600
+	}
+
+	// 未配置审核模式时自动通过
+	if GlobalConfig.Moderation.Mode == "" {
+		GlobalConfig.Moderation.Mode = ModerationAuto
+	}
+
+	// 未配置问答分类时放在法学交流社区，最高悬赏默认 500 积分
+	if GlobalConfig.QA.CategoryID <= 0 {
+		GlobalConfig.QA.CategoryID = 1
+	}
+	if GlobalConfig.QA.MaxBounty <= 0 {
+		GlobalConfig.QA.MaxBounty = 500
+	}
+
+	// 未配置行政区划代码文件时使用随程序发布的文件
+	if GlobalConfig.Regions.File == "" {
+		GlobalConfig.Regions.File = "data/gbt2260.txt"
+	}
+
+	// 未配置法条链接前缀时链接到前端的法规页面
+	if GlobalConfig.Statute.LinkPrefix == "" {
+		GlobalConfig.Statute.LinkPrefix = "/statutes"
+	}
+
+	// 未配置栏目时使用默认栏目
+	if len(GlobalConfig.Sections) == 0 {
+		GlobalConfig.Sections = DefaultSections
+	}
+
 	log.Println("配置加载成功")
 }
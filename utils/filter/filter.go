@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Result 内容检查结果
+type Result struct {
+	Action Action   // 命中规则中最严重的处理方式，未命中时为空
+	Text   string   // 处理后的内容：mask 规则命中的部分已替换为 *
+	Words  []string // 命中的敏感词（去重）
+}
+
+// Blocked 内容是否应被拒绝
+func (r Result) Blocked() bool {
+	return r.Action == ActionBlock
+}
+
+// NeedsReview 内容是否需要人工审核
+func (r Result) NeedsReview() bool {
+	return r.Action == ActionReview
+}
+
+// current 当前生效的匹配器，重新加载时整体替换，读取无需加锁
+var current atomic.Pointer[Matcher]
+
+func init() {
+	current.Store(NewMatcher(nil))
+}
+
+// Reload 使用新的规则替换当前匹配器
+func Reload(rules []Rule) {
+	current.Store(NewMatcher(rules))
+	log.Printf("敏感词库已加载，共 %d 条", len(rules))
+}
+
+// Check 使用当前匹配器检查内容
+func Check(text string) Result {
+	return check(current.Load(), text)
+}
+
+// check 检查内容并对 mask 规则命中的部分打码
+func check(m *Matcher, text string) Result {
+	result := Result{Text: text}
+	hits := m.FindAll(text)
+	if len(hits) == 0 {
+		return result
+	}
+
+	runes := []rune(text)
+	seen := make(map[string]bool)
+	for _, hit := range hits {
+		if severity[hit.Rule.Action] > severity[result.Action] {
+			result.Action = hit.Rule.Action
+		}
+		if !seen[hit.Rule.Word] {
+			seen[hit.Rule.Word] = true
+			result.Words = append(result.Words, hit.Rule.Word)
+		}
+		if hit.Rule.Action == ActionMask {
+			for i := hit.Start; i < hit.End; i++ {
+				runes[i] = '*'
+			}
+		}
+	}
+	result.Text = string(runes)
+	return result
+}
+
+// Merge 合并多个检查结果的处理方式和命中词，Text 字段不合并
+func Merge(results ...Result) Result {
+	var merged Result
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if severity[r.Action] > severity[merged.Action] {
+			merged.Action = r.Action
+		}
+		for _, word := range r.Words {
+			if !seen[word] {
+				seen[word] = true
+				merged.Words = append(merged.Words, word)
+			}
+		}
+	}
+	return merged
+}
+
+// StartAutoReload 定期通过 load 重新加载规则，interval 不大于 0 时不启动
+// 用于多实例部署时同步其他实例对词库的修改
+func StartAutoReload(interval time.Duration, load func() ([]Rule, error)) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			rules, err := load()
+			if err != nil {
+				log.Printf("重新加载敏感词库失败: %v", err)
+				continue
+			}
+			current.Store(NewMatcher(rules))
+		}
+	}()
+}
@@ -0,0 +1,160 @@
+package filter
+
+import (
+	"unicode"
+)
+
+// Action 命中敏感词后的处理方式
+type Action string
+
+// 处理方式，按严重程度从低到高排列
+const (
+	ActionMask   Action = "mask"   // 用 * 替换敏感词
+	ActionReview Action = "review" // 转入人工审核
+	ActionBlock  Action = "block"  // 拒绝提交
+)
+
+// severity 处理方式的严重程度，用于多个敏感词同时命中时取最严重的处理方式
+var severity = map[Action]int{
+	ActionMask:   1,
+	ActionReview: 2,
+	ActionBlock:  3,
+}
+
+// Valid 检查处理方式是否受支持
+func (a Action) Valid() bool {
+	_, ok := severity[a]
+	return ok
+}
+
+// Rule 一条敏感词规则
+type Rule struct {
+	Word   string `json:"word"`
+	Action Action `json:"action"`
+}
+
+// Hit 一次命中，Start/End 为正文中的字符（rune）下标，左闭右开
+type Hit struct {
+	Rule  Rule
+	Start int
+	End   int
+}
+
+// node Aho-Corasick 自动机节点
+type node struct {
+	next   map[rune]int
+	fail   int
+	output []int // 在此节点结束的规则下标（含经失败链接可达的规则）
+}
+
+// Matcher 基于 Aho-Corasick 自动机的多模式匹配器，构建后只读，可并发使用
+type Matcher struct {
+	nodes []node
+	rules []Rule
+	words [][]rune
+}
+
+// NewMatcher 根据规则构建匹配器，空词和重复词会被忽略（重复词保留最严重的处理方式）
+func NewMatcher(rules []Rule) *Matcher {
+	m := &Matcher{nodes: []node{{next: map[rune]int{}}}}
+
+	// 1. 构建字典树
+	index := make(map[string]int)
+	for _, rule := range rules {
+		word := normalize([]rune(rule.Word))
+		if len(word) == 0 || !rule.Action.Valid() {
+			continue
+		}
+		if i, ok := index[string(word)]; ok {
+			if severity[rule.Action] > severity[m.rules[i].Action] {
+				m.rules[i].Action = rule.Action
+			}
+			continue
+		}
+
+		cur := 0
+		for _, r := range word {
+			nxt, ok := m.nodes[cur].next[r]
+			if !ok {
+				m.nodes = append(m.nodes, node{next: map[rune]int{}})
+				nxt = len(m.nodes) - 1
+				m.nodes[cur].next[r] = nxt
+			}
+			cur = nxt
+		}
+		index[string(word)] = len(m.rules)
+		m.nodes[cur].output = append(m.nodes[cur].output, len(m.rules))
+		m.rules = append(m.rules, rule)
+		m.words = append(m.words, word)
+	}
+
+	// 2. 广度优先构建失败链接
+	queue := make([]int, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if target, ok := m.nodes[fail].next[r]; ok && target != child {
+				m.nodes[child].fail = target
+			}
+			m.nodes[child].output = append(m.nodes[child].output, m.nodes[m.nodes[child].fail].output...)
+			queue = append(queue, child)
+		}
+	}
+	return m
+}
+
+// Len 返回规则数量
+func (m *Matcher) Len() int {
+	return len(m.rules)
+}
+
+// FindAll 返回正文中所有命中（可能相互重叠），按结束位置排列
+func (m *Matcher) FindAll(text string) []Hit {
+	var hits []Hit
+	cur := 0
+	for i, r := range normalize([]rune(text)) {
+		for cur != 0 {
+			if _, ok := m.nodes[cur].next[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if nxt, ok := m.nodes[cur].next[r]; ok {
+			cur = nxt
+		}
+		for _, ruleIndex := range m.nodes[cur].output {
+			hits = append(hits, Hit{
+				Rule:  m.rules[ruleIndex],
+				Start: i + 1 - len(m.words[ruleIndex]),
+				End:   i + 1,
+			})
+		}
+	}
+	return hits
+}
+
+// normalize 统一大小写并将全角字符转为半角，保持字符数不变以便定位原文
+func normalize(runes []rune) []rune {
+	out := make([]rune, len(runes))
+	for i, r := range runes {
+		switch {
+		case r == '　':
+			r = ' '
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		}
+		out[i] = unicode.ToLower(r)
+	}
+	return out
+}
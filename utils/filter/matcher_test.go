@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// hitStrings 将命中转换为 "词@起-止" 并排序，便于比较
+func hitStrings(hits []Hit) []string {
+	out := make([]string, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, fmt.Sprintf("%s@%d-%d", h.Rule.Word, h.Start, h.End))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestMatcherFindAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		text  string
+		want  []string
+	}{
+		{
+			name:  "no rules",
+			rules: nil,
+			text:  "任意内容",
+			want:  []string{},
+		},
+		{
+			name:  "single word",
+			rules: []Rule{{"赌博", ActionBlock}},
+			text:  "禁止赌博行为",
+			want:  []string{"赌博@2-4"},
+		},
+		{
+			name:  "repeated occurrences",
+			rules: []Rule{{"ab", ActionMask}},
+			text:  "ab-ab",
+			want:  []string{"ab@0-2", "ab@3-5"},
+		},
+		{
+			name:  "overlapping and nested words",
+			rules: []Rule{{"he", ActionMask}, {"she", ActionMask}, {"his", ActionMask}, {"hers", ActionMask}},
+			text:  "ushers",
+			want:  []string{"he@2-4", "hers@2-6", "she@1-4"},
+		},
+		{
+			name:  "case insensitive",
+			rules: []Rule{{"Spam", ActionReview}},
+			text:  "SPAM and spam",
+			want:  []string{"Spam@0-4", "Spam@9-13"},
+		},
+		{
+			name:  "full width characters",
+			rules: []Rule{{"qq", ActionReview}},
+			text:  "加ＱＱ联系",
+			want:  []string{"qq@1-3"},
+		},
+		{
+			name:  "empty word and invalid action ignored",
+			rules: []Rule{{"", ActionBlock}, {"词", Action("unknown")}},
+			text:  "词",
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitStrings(NewMatcher(tt.rules).FindAll(tt.text))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherDuplicateSeverity(t *testing.T) {
+	m := NewMatcher([]Rule{{"词", ActionMask}, {"词", ActionBlock}, {"词", ActionReview}})
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", m.Len())
+	}
+	if hits := m.FindAll("词"); len(hits) != 1 || hits[0].Rule.Action != ActionBlock {
+		t.Errorf("FindAll = %+v, want one block hit", hits)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	m := NewMatcher([]Rule{{"笨蛋", ActionMask}, {"代考", ActionReview}, {"枪支", ActionBlock}})
+	tests := []struct {
+		name       string
+		text       string
+		wantAction Action
+		wantText   string
+		wantWords  []string
+	}{
+		{"clean", "正常内容", "", "正常内容", nil},
+		{"mask", "你这个笨蛋", ActionMask, "你这个**", []string{"笨蛋"}},
+		{"review wins over mask", "笨蛋找代考", ActionReview, "**找代考", []string{"笨蛋", "代考"}},
+		{"block wins", "代考和枪支", ActionBlock, "代考和枪支", []string{"代考", "枪支"}},
+		{"repeated word listed once", "笨蛋笨蛋", ActionMask, "****", []string{"笨蛋"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := check(m, tt.text)
+			if got.Action != tt.wantAction || got.Text != tt.wantText || !reflect.DeepEqual(got.Words, tt.wantWords) {
+				t.Errorf("check(%q) = %+v, want action %q text %q words %v", tt.text, got, tt.wantAction, tt.wantText, tt.wantWords)
+			}
+		})
+	}
+}
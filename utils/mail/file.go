@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer 将邮件追加写入本地文件，Path 为空时写入日志，用于开发环境查看邮件内容
+type FileMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Send 写入邮件
+func (m *FileMailer) Send(msg Message) error {
+	entry := fmt.Sprintf("[%s] To: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	if m.Path == "" {
+		log.Printf("邮件（未实际发送）:\n%s", entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(entry)
+	return err
+}
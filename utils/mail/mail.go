@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+)
+
+// Message 邮件内容
+type Message struct {
+	To      string
+	Subject string
+	Body    string // 纯文本正文
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// 发送方式
+const (
+	DriverSMTP   = "smtp"   // 通过 SMTP 服务器发送
+	DriverFile   = "file"   // 写入本地文件，未配置路径时写入日志，适用于开发环境
+	DriverMemory = "memory" // 保存在内存中，适用于测试
+)
+
+// Options 邮件发送配置
+type Options struct {
+	Driver   string
+	From     string // 发件人地址
+	Host     string // SMTP 服务器
+	Port     int    // SMTP 端口，465 使用隐式 TLS，其他端口在服务器支持时使用 STARTTLS
+	Username string
+	Password string
+	Path     string // file 方式的输出文件
+}
+
+// Default 全局邮件发送器，未初始化时写入日志
+var Default Mailer = &FileMailer{}
+
+// Init 根据配置初始化全局邮件发送器
+func Init(opts Options) error {
+	switch opts.Driver {
+	case DriverSMTP:
+		if opts.Host == "" || opts.From == "" {
+			return fmt.Errorf("SMTP 发送需要配置 host 和 from")
+		}
+		Default = &SMTPMailer{
+			Host:     opts.Host,
+			Port:     opts.Port,
+			Username: opts.Username,
+			Password: opts.Password,
+			From:     opts.From,
+		}
+	case DriverMemory:
+		Default = NewMemoryMailer()
+	case DriverFile, "":
+		Default = &FileMailer{Path: opts.Path}
+	default:
+		return fmt.Errorf("不支持的邮件发送方式: %s", opts.Driver)
+	}
+	return nil
+}
+
+// Send 使用全局邮件发送器发送邮件
+func Send(msg Message) error {
+	return Default.Send(msg)
+}
+
+// SendAsync 在后台发送邮件，失败时只记录日志，避免阻塞请求或暴露发送耗时
+func SendAsync(msg Message) {
+	mailer := Default
+	go func() {
+		if err := mailer.Send(msg); err != nil {
+			log.Printf("发送邮件到 %s 失败: %v", msg.To, err)
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package region
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// 行政区划级别
+const (
+	LevelProvince = 1 // 省级
+	LevelCity     = 2 // 地级
+	LevelDistrict = 3 // 县级
+)
+
+// codePattern 行政区划代码（6 位数字）
+var codePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// Region GB/T 2260 行政区划
+type Region struct {
+	Code       string
+	Name       string
+	ParentCode string // 省级区划为空
+	Level      int
+}
+
+// ValidCode 检查行政区划代码格式是否正确
+func ValidCode(code string) bool {
+	return codePattern.MatchString(code)
+}
+
+// LoadFile 读取 GB/T 2260 行政区划代码文件
+func LoadFile(path string) ([]Region, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse 解析行政区划代码：每行一个区划，代码与名称以空白分隔，空行和 # 开头的行忽略。
+// 级别和上级区划由代码推导：XX0000 为省级，XXYY00 为地级，其余为县级，
+// 县级区划所属的地级区划不存在时（如直辖市的区、省直辖县）直接归属省级区划
+func Parse(r io.Reader) ([]Region, error) {
+	var regions []Region
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 || !ValidCode(fields[0]) {
+			return nil, fmt.Errorf("第 %d 行格式不正确: %s", line, text)
+		}
+		code := fields[0]
+		if seen[code] {
+			return nil, fmt.Errorf("第 %d 行代码重复: %s", line, code)
+		}
+		seen[code] = true
+		regions = append(regions, Region{Code: code, Name: strings.Join(fields[1:], " ")})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range regions {
+		r := &regions[i]
+		province, city := r.Code[:2]+"0000", r.Code[:4]+"00"
+		switch {
+		case r.Code == province:
+			r.Level = LevelProvince
+		case r.Code == city:
+			r.Level, r.ParentCode = LevelCity, province
+		case seen[city]:
+			r.Level, r.ParentCode = LevelDistrict, city
+		default:
+			r.Level, r.ParentCode = LevelDistrict, province
+		}
+		if r.ParentCode != "" && !seen[r.ParentCode] {
+			return nil, fmt.Errorf("区划 %s 的上级区划 %s 不存在", r.Code, r.ParentCode)
+		}
+	}
+	return regions, nil
+}
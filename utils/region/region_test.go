@@ -0,0 +1,85 @@
+package region
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Region
+		wantErr bool
+	}{
+		{
+			name: "province city and district",
+			input: `# 注释行
+110000 北京市
+110101 东城区
+
+440000 广东省
+440100 广州市
+440103 荔湾区
+`,
+			want: []Region{
+				{Code: "110000", Name: "北京市", Level: LevelProvince},
+				{Code: "110101", Name: "东城区", ParentCode: "110000", Level: LevelDistrict},
+				{Code: "440000", Name: "广东省", Level: LevelProvince},
+				{Code: "440100", Name: "广州市", ParentCode: "440000", Level: LevelCity},
+				{Code: "440103", Name: "荔湾区", ParentCode: "440100", Level: LevelDistrict},
+			},
+		},
+		{
+			name:  "county directly under province",
+			input: "420000 湖北省\n429004 仙桃市\n",
+			want: []Region{
+				{Code: "420000", Name: "湖北省", Level: LevelProvince},
+				{Code: "429004", Name: "仙桃市", ParentCode: "420000", Level: LevelDistrict},
+			},
+		},
+		{
+			name:  "name with spaces and tabs",
+			input: "650000\t新疆维吾尔自治区\n652300  昌吉 回族自治州\n",
+			want: []Region{
+				{Code: "650000", Name: "新疆维吾尔自治区", Level: LevelProvince},
+				{Code: "652300", Name: "昌吉 回族自治州", ParentCode: "650000", Level: LevelCity},
+			},
+		},
+		{name: "empty", input: "\n# 只有注释\n", want: nil},
+		{name: "missing name", input: "110000\n", wantErr: true},
+		{name: "short code", input: "11000 北京市\n", wantErr: true},
+		{name: "non numeric code", input: "11000A 北京市\n", wantErr: true},
+		{name: "duplicate code", input: "110000 北京市\n110000 北京\n", wantErr: true},
+		{name: "missing province", input: "440100 广州市\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadBundledFile(t *testing.T) {
+	regions, err := LoadFile("../../data/gbt2260.txt")
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	levels := make(map[int]int)
+	for _, r := range regions {
+		levels[r.Level]++
+	}
+	if levels[LevelProvince] != 34 {
+		t.Errorf("province-level regions = %d, want 34", levels[LevelProvince])
+	}
+	if levels[LevelCity] == 0 || levels[LevelDistrict] == 0 {
+		t.Errorf("levels = %v, want city and district regions", levels)
+	}
+}
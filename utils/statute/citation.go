@@ -0,0 +1,92 @@
+package statute
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// citationTitlePattern 书名号中的法规名称
+	citationTitlePattern = regexp.MustCompile(`《([^《》\n]{1,60})》`)
+	// citationArticlePattern 紧跟在法规名称或上一条之后的条文，如 第1165条、第一百三十三条之一第二款；
+	// 第一条之后的条文以顿号、逗号、和、及、以及、与连接
+	citationArticlePattern = regexp.MustCompile(`^(、|，|,|和|及|以及|与)?第(` + numberPattern + `)条(?:之(` + numberPattern + `))?` +
+		`(?:第(?:` + numberPattern + `)款)?(?:第(?:` + numberPattern + `)项)?`)
+)
+
+// Citation 正文中对法规条文的一处引用
+type Citation struct {
+	Title     string // 书名号中的法规名称
+	Number    int
+	SubNumber int
+	Start     int // 引用文字在正文中的起止字节位置，第一条包含书名号和法规名称
+	End       int
+}
+
+// FindCitations 识别正文中的法规条文引用，如 《民法典》第1165条、《中华人民共和国民法典》第一千一百六十五条第一款、
+// 《刑法》第一百三十三条之一；同一法规连续引用多条时（如 《民法典》第1165条、第1166条）每条各为一处引用
+func FindCitations(content string) []Citation {
+	var citations []Citation
+	for _, m := range citationTitlePattern.FindAllStringSubmatchIndex(content, -1) {
+		title := strings.TrimSpace(content[m[2]:m[3]])
+		first, pos := true, m[1]
+		for {
+			a := citationArticlePattern.FindStringSubmatchIndex(content[pos:])
+			if a == nil || (first && a[2] >= 0) {
+				break
+			}
+			number, ok := ParseNumber(content[pos+a[4] : pos+a[5]])
+			if !ok {
+				break
+			}
+			subNumber := 0
+			if a[6] >= 0 {
+				if subNumber, ok = ParseNumber(content[pos+a[6] : pos+a[7]]); !ok {
+					break
+				}
+			}
+			start := m[0]
+			if !first {
+				start = pos + a[0]
+				if a[2] >= 0 {
+					start = pos + a[3] // 不包含连接词
+				}
+			}
+			citations = append(citations, Citation{Title: title, Number: number, SubNumber: subNumber, Start: start, End: pos + a[1]})
+			pos += a[1]
+			first = false
+		}
+	}
+	return citations
+}
+
+// NormalizeTitle 统一法规名称的写法：去掉“中华人民共和国”前缀，用于匹配全称和通用简称
+func NormalizeTitle(title string) string {
+	return strings.TrimPrefix(strings.TrimSpace(title), "中华人民共和国")
+}
+
+// Link 将正文中的法规条文引用转换为 Markdown 链接，url 返回引用对应的链接，无法识别的引用保持原样；
+// 已经是链接文字的引用（前面是 [）不重复转换
+func Link(content string, url func(c Citation) (string, bool)) string {
+	citations := FindCitations(content)
+	if len(citations) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, c := range citations {
+		if c.Start > 0 && content[c.Start-1] == '[' {
+			continue
+		}
+		target, ok := url(c)
+		if !ok {
+			continue
+		}
+		b.WriteString(content[last:c.Start])
+		b.WriteString("[" + content[c.Start:c.End] + "](" + target + ")")
+		last = c.End
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
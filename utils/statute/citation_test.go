@@ -0,0 +1,123 @@
+package statute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"1165", 1165, true},
+		{"1", 1, true},
+		{"9999", 9999, true},
+		{"0", 0, false},
+		{"10000", 10000, false},
+		{"一", 1, true},
+		{"十", 10, true},
+		{"十五", 15, true},
+		{"二十", 20, true},
+		{"一百十", 110, true},
+		{"一百零五", 105, true},
+		{"一百三十三", 133, true},
+		{"两千", 2000, true},
+		{"一千一百六十五", 1165, true},
+		{"一千〇一", 1001, true},
+		{"九千九百九十九", 9999, true},
+		{"", 0, false},
+		{"零", 0, false},
+		{"一二", 0, false},
+		{"百", 0, false},
+		{"一万", 0, false},
+		{"第一", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseNumber(tt.in)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("ParseNumber(%q) = (%d, %v), want (%d, %v)", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// citationText 引用在正文中的文字及解析结果，便于比较
+type citationText struct {
+	Title     string
+	Number    int
+	SubNumber int
+	Text      string
+}
+
+func TestFindCitations(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []citationText
+	}{
+		{
+			name:    "arabic number",
+			content: "根据《民法典》第1165条，行为人应当承担侵权责任。",
+			want:    []citationText{{"民法典", 1165, 0, "《民法典》第1165条"}},
+		},
+		{
+			name:    "chinese number with paragraph",
+			content: "《中华人民共和国民法典》第一千一百六十五条第一款规定",
+			want:    []citationText{{"中华人民共和国民法典", 1165, 0, "《中华人民共和国民法典》第一千一百六十五条第一款"}},
+		},
+		{
+			name:    "sub number and item",
+			content: "《刑法》第一百三十三条之一第一款第二项",
+			want:    []citationText{{"刑法", 133, 1, "《刑法》第一百三十三条之一第一款第二项"}},
+		},
+		{
+			name:    "consecutive articles",
+			content: "见《民法典》第1165条、第1166条和第1167条。",
+			want: []citationText{
+				{"民法典", 1165, 0, "《民法典》第1165条"},
+				{"民法典", 1166, 0, "第1166条"},
+				{"民法典", 1167, 0, "第1167条"},
+			},
+		},
+		{
+			name:    "multiple statutes",
+			content: "《刑法》第20条与《民法典》第181条",
+			want: []citationText{
+				{"刑法", 20, 0, "《刑法》第20条"},
+				{"民法典", 181, 0, "《民法典》第181条"},
+			},
+		},
+		{
+			name:    "title without article",
+			content: "请阅读《民法典》全文",
+			want:    nil,
+		},
+		{
+			name:    "connector before first article",
+			content: "《民法典》、第1165条",
+			want:    nil,
+		},
+		{
+			name:    "invalid number",
+			content: "《民法典》第0条",
+			want:    nil,
+		},
+		{
+			name:    "text between title and article",
+			content: "《民法典》的第1165条",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []citationText
+			for _, c := range FindCitations(tt.content) {
+				got = append(got, citationText{c.Title, c.Number, c.SubNumber, tt.content[c.Start:c.End]})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCitations(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package statute
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 中文数字
+var (
+	chineseDigits = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	digitValues   = map[rune]int{
+		'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+		'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+	}
+	unitValues = map[rune]int{'十': 10, '百': 100, '千': 1000}
+)
+
+// numberPattern 条、款、项序号可使用的字符，用于拼接正则表达式
+const numberPattern = `[0-9]+|[零〇一二两三四五六七八九十百千]+`
+
+// ParseNumber 解析阿拉伯数字或中文数字（如 1165、一千一百六十五），只支持 1 到 9999
+func ParseNumber(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n > 0 && n < 10000
+	}
+
+	total, current := 0, -1
+	for _, r := range s {
+		if d, ok := digitValues[r]; ok {
+			if current > 0 {
+				return 0, false // 连续的非零数字（如 一二）不是合法写法
+			}
+			current = d
+			continue
+		}
+		unit, ok := unitValues[r]
+		if !ok {
+			return 0, false
+		}
+		if current < 0 {
+			if unit != 10 {
+				return 0, false
+			}
+			current = 1 // 十五、一百十 省略了 一
+		}
+		total += current * unit
+		current = -1
+	}
+	if current > 0 {
+		total += current
+	}
+	return total, total > 0 && total < 10000
+}
+
+// FormatNumber 将 1 到 9999 的整数转换为中文数字，如 1165 转换为 一千一百六十五
+func FormatNumber(n int) string {
+	if n <= 0 || n >= 10000 {
+		return strconv.Itoa(n)
+	}
+
+	var b strings.Builder
+	zero := false
+	for _, u := range []struct {
+		value int
+		name  string
+	}{{1000, "千"}, {100, "百"}, {10, "十"}} {
+		d := n / u.value
+		n %= u.value
+		if d == 0 {
+			zero = b.Len() > 0
+			continue
+		}
+		if zero {
+			b.WriteString("零")
+			zero = false
+		}
+		// 十到十九省略开头的 一
+		if !(u.value == 10 && d == 1 && b.Len() == 0) {
+			b.WriteString(chineseDigits[d])
+		}
+		b.WriteString(u.name)
+	}
+	if n > 0 {
+		if zero {
+			b.WriteString("零")
+		}
+		b.WriteString(chineseDigits[n])
+	}
+	return b.String()
+}
+
+// ArticleLabel 生成条文序号，如 第一千一百六十五条、第一百三十三条之一
+func ArticleLabel(number, subNumber int) string {
+	label := "第" + FormatNumber(number) + "条"
+	if subNumber > 0 {
+		label += "之" + FormatNumber(subNumber)
+	}
+	return label
+}
+
+// ArticleRef 生成条文在链接中的序号，如 1165、133-1
+func ArticleRef(number, subNumber int) string {
+	ref := strconv.Itoa(number)
+	if subNumber > 0 {
+		ref += "-" + strconv.Itoa(subNumber)
+	}
+	return ref
+}
+
+// ParseArticleRef 解析 ArticleRef 生成的序号
+func ParseArticleRef(ref string) (number, subNumber int, ok bool) {
+	main, sub, hasSub := strings.Cut(ref, "-")
+	number, err := strconv.Atoi(main)
+	if err != nil || number <= 0 {
+		return 0, 0, false
+	}
+	if hasSub {
+		if subNumber, err = strconv.Atoi(sub); err != nil || subNumber <= 0 {
+			return 0, 0, false
+		}
+	}
+	return number, subNumber, true
+}
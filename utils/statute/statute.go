@@ -0,0 +1,261 @@
+package statute
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 结构层级类型
+const (
+	KindPart    = "part"    // 编
+	KindSubpart = "subpart" // 分编
+	KindChapter = "chapter" // 章
+	KindSection = "section" // 节
+)
+
+// DateLayout 施行日期格式
+const DateLayout = "2006-01-02"
+
+var (
+	// nodeTitlePattern 编、分编、章、节的标题，如 第一编 总则
+	nodeTitlePattern = regexp.MustCompile(`^第(?:` + numberPattern + `)(分编|编|章|节)`)
+	// articleLinePattern Markdown 中一条的开头，如 第一条　为了保护……
+	articleLinePattern = regexp.MustCompile(`^第(` + numberPattern + `)条(?:之(` + numberPattern + `))?(?:[\s\x{3000}]+(.*))?$`)
+	// headingPattern Markdown 标题
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
+	// metaPattern Markdown 开头的法规信息，如 施行日期：2021-01-01
+	metaPattern = regexp.MustCompile(`^(简称|制定机关|发布机关|施行日期|版本说明)\s*[:：]\s*(.+)$`)
+)
+
+// Law 一部法律法规的一个版本
+type Law struct {
+	Title         string     `json:"title"`          // 全称，如 中华人民共和国民法典
+	ShortTitle    string     `json:"short_title"`    // 简称，如 民法典
+	Authority     string     `json:"authority"`      // 制定机关
+	EffectiveDate string     `json:"effective_date"` // 该版本的施行日期
+	Note          string     `json:"note"`           // 版本说明，如 根据某决定修正
+	Nodes         []*Node    `json:"nodes"`          // 编、章、节
+	Articles      []*Article `json:"articles"`       // 不属于任何章节的条文
+}
+
+// Node 编、分编、章、节
+type Node struct {
+	Kind     string     `json:"kind"` // 为空时根据标题推断
+	Title    string     `json:"title"`
+	Nodes    []*Node    `json:"nodes"`
+	Articles []*Article `json:"articles"`
+}
+
+// Article 条文
+type Article struct {
+	Number    int    `json:"number"`     // 条号
+	SubNumber int    `json:"sub_number"` // 之几，如 第一百三十三条之一 为 1，没有时为 0
+	Content   string `json:"content"`
+}
+
+// NodeKind 根据标题推断结构层级类型，无法推断时返回空字符串
+func NodeKind(title string) string {
+	m := nodeTitlePattern.FindStringSubmatch(title)
+	if m == nil {
+		return ""
+	}
+	switch m[1] {
+	case "编":
+		return KindPart
+	case "分编":
+		return KindSubpart
+	case "章":
+		return KindChapter
+	default:
+		return KindSection
+	}
+}
+
+// ParseJSON 解析 JSON 格式的法规
+func ParseJSON(r io.Reader) (*Law, error) {
+	var law Law
+	if err := json.NewDecoder(r).Decode(&law); err != nil {
+		return nil, fmt.Errorf("JSON 格式不正确: %w", err)
+	}
+	if err := law.Validate(); err != nil {
+		return nil, err
+	}
+	return &law, nil
+}
+
+// ParseMarkdown 解析 Markdown 格式的法规：
+//
+//	# 中华人民共和国民法典
+//	简称：民法典
+//	制定机关：全国人民代表大会
+//	施行日期：2021-01-01
+//	## 第一编 总则
+//	### 第一章 基本规定
+//	第一条　为了保护民事主体的合法权益……
+//
+// 一级标题为法规全称，其后可写 简称、制定机关（或发布机关）、施行日期、版本说明；
+// 其余标题为编、章、节，按标题级别嵌套；以“第某条”加空白开头的行开始一条，后续的行并入该条；
+// 第一条之前不属于任何条文的文字（如目录、序言）忽略
+func ParseMarkdown(r io.Reader) (*Law, error) {
+	law := &Law{}
+	type frame struct {
+		level int
+		node  *Node
+	}
+	var stack []frame
+	var current *Article
+	started := false // 已经出现章节或条文，此后不再读取法规信息
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(text); m != nil {
+			level, title := len(m[1]), strings.TrimSpace(m[2])
+			if level == 1 && law.Title == "" && !started {
+				law.Title = title
+				continue
+			}
+			started = true
+			current = nil
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			node := &Node{Kind: NodeKind(title), Title: title}
+			if len(stack) == 0 {
+				law.Nodes = append(law.Nodes, node)
+			} else {
+				parent := stack[len(stack)-1].node
+				parent.Nodes = append(parent.Nodes, node)
+			}
+			stack = append(stack, frame{level, node})
+			continue
+		}
+
+		if !started {
+			if m := metaPattern.FindStringSubmatch(text); m != nil {
+				switch m[1] {
+				case "简称":
+					law.ShortTitle = m[2]
+				case "制定机关", "发布机关":
+					law.Authority = m[2]
+				case "施行日期":
+					law.EffectiveDate = m[2]
+				case "版本说明":
+					law.Note = m[2]
+				}
+				continue
+			}
+		}
+
+		if m := articleLinePattern.FindStringSubmatch(text); m != nil {
+			number, ok := ParseNumber(m[1])
+			if !ok {
+				return nil, fmt.Errorf("第 %d 行条号不正确: %s", line, text)
+			}
+			subNumber := 0
+			if m[2] != "" {
+				if subNumber, ok = ParseNumber(m[2]); !ok {
+					return nil, fmt.Errorf("第 %d 行条号不正确: %s", line, text)
+				}
+			}
+			started = true
+			current = &Article{Number: number, SubNumber: subNumber, Content: strings.TrimSpace(m[3])}
+			if len(stack) == 0 {
+				law.Articles = append(law.Articles, current)
+			} else {
+				parent := stack[len(stack)-1].node
+				parent.Articles = append(parent.Articles, current)
+			}
+			continue
+		}
+
+		if current != nil {
+			if current.Content != "" {
+				current.Content += "\n"
+			}
+			current.Content += text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := law.Validate(); err != nil {
+		return nil, err
+	}
+	return law, nil
+}
+
+// Validate 检查法规信息是否完整、条号是否重复，并补全章节类型
+func (law *Law) Validate() error {
+	law.Title = strings.TrimSpace(law.Title)
+	law.ShortTitle = strings.TrimSpace(law.ShortTitle)
+	if law.Title == "" {
+		return errors.New("缺少法规名称")
+	}
+	if len([]rune(law.Title)) > 200 || len([]rune(law.ShortTitle)) > 100 || len([]rune(law.Authority)) > 100 || len([]rune(law.Note)) > 255 {
+		return errors.New("法规名称、简称、制定机关或版本说明过长")
+	}
+	if _, err := time.Parse(DateLayout, law.EffectiveDate); err != nil {
+		return errors.New("施行日期格式应为 2006-01-02")
+	}
+
+	seen := make(map[[2]int]bool)
+	count := 0
+	var check func(nodes []*Node, articles []*Article) error
+	check = func(nodes []*Node, articles []*Article) error {
+		for _, a := range articles {
+			if a == nil || a.Number <= 0 || a.SubNumber < 0 {
+				return errors.New("条号必须为正整数")
+			}
+			label := ArticleLabel(a.Number, a.SubNumber)
+			if strings.TrimSpace(a.Content) == "" {
+				return fmt.Errorf("%s 内容为空", label)
+			}
+			key := [2]int{a.Number, a.SubNumber}
+			if seen[key] {
+				return fmt.Errorf("%s 重复", label)
+			}
+			seen[key] = true
+			count++
+		}
+		for _, n := range nodes {
+			if n == nil || strings.TrimSpace(n.Title) == "" {
+				return errors.New("章节标题不能为空")
+			}
+			if len([]rune(n.Title)) > 200 {
+				return fmt.Errorf("章节标题过长: %s", n.Title)
+			}
+			if n.Kind == "" {
+				n.Kind = NodeKind(n.Title)
+			}
+			switch n.Kind {
+			case "", KindPart, KindSubpart, KindChapter, KindSection:
+			default:
+				return fmt.Errorf("章节类型不正确: %s", n.Kind)
+			}
+			if err := check(n.Nodes, n.Articles); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(law.Nodes, law.Articles); err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("法规没有条文")
+	}
+	return nil
+}
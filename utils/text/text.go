@@ -0,0 +1,207 @@
+package text
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 阅读速度估算值
+const (
+	charsPerMinute = 400 // 中文每分钟阅读字数
+	wordsPerMinute = 200 // 英文每分钟阅读单词数
+)
+
+var (
+	// 匹配 Markdown 图片 ![alt](url) 与 HTML <img src="url">
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\(\s*([^)\s]+)[^)]*\)`)
+	htmlImage     = regexp.MustCompile(`(?i)<img[^>]+src\s*=\s*["']([^"']+)["']`)
+
+	// 摘要生成时需要去除的标记
+	htmlTag       = regexp.MustCompile(`<[^>]*>`)
+	markdownLink  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownMarks = regexp.MustCompile("(?m)^\\s*(#{1,6}|>|[-*+]|\\d+\\.)\\s+|[*_`~]+")
+)
+
+// FirstImageURL 返回正文中第一张图片的地址，没有图片时返回空字符串
+func FirstImageURL(content string) string {
+	md := markdownImage.FindStringSubmatchIndex(content)
+	html := htmlImage.FindStringSubmatchIndex(content)
+	switch {
+	case md == nil && html == nil:
+		return ""
+	case html == nil || (md != nil && md[0] < html[0]):
+		return content[md[2]:md[3]]
+	default:
+		return content[html[2]:html[3]]
+	}
+}
+
+// PlainText 去除 Markdown/HTML 标记并压缩空白
+func PlainText(content string) string {
+	content = markdownImage.ReplaceAllString(content, "")
+	content = htmlTag.ReplaceAllString(content, "")
+	content = markdownLink.ReplaceAllString(content, "$1")
+	content = markdownMarks.ReplaceAllString(content, "")
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// Excerpt 生成不超过 maxRunes 个字符的纯文本摘要，截断时追加省略号
+func Excerpt(content string, maxRunes int) string {
+	plain := PlainText(content)
+	if utf8.RuneCountInString(plain) <= maxRunes {
+		return plain
+	}
+	runes := []rune(plain)
+	return strings.TrimSpace(string(runes[:maxRunes])) + "…"
+}
+
+// ReadingMinutes 估算阅读时长（分钟），中文按字数、其他文字按单词数计算，至少为 1 分钟
+func ReadingMinutes(content string) int {
+	cjk, words := countReading(content)
+	return readingMinutes(cjk, words)
+}
+
+// ReadingMinutesSample 根据正文开头的片段和正文总字符数估算阅读时长，
+// 片段中的中文字数和单词数按总长度等比放大，用于列表中不读取完整正文的场景
+func ReadingMinutesSample(sample string, totalRunes int) int {
+	cjk, words := countReading(sample)
+	if n := utf8.RuneCountInString(sample); n > 0 && totalRunes > n {
+		cjk = cjk * totalRunes / n
+		words = words * totalRunes / n
+	}
+	return readingMinutes(cjk, words)
+}
+
+// countReading 统计中文字数和其他文字的单词数
+func countReading(content string) (cjk, words int) {
+	inWord := false
+	for _, r := range content {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return cjk, words
+}
+
+// readingMinutes 按阅读速度换算分钟数，至少为 1 分钟
+func readingMinutes(cjk, words int) int {
+	minutes := cjk/charsPerMinute + words/wordsPerMinute
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// Highlight 截取正文中首个关键词附近不超过 maxRunes 个字符的片段，
+// 并用 <em></em> 包裹所有命中的关键词（片段内容会先做 HTML 转义）
+func Highlight(content string, terms []string, maxRunes int) string {
+	runes := []rune(PlainText(content))
+	lower, keys := prepareHighlight(runes, terms)
+
+	// 定位第一个命中位置，让片段以它为中心
+	start := 0
+	if first := indexAny(lower, keys, 0); first >= 0 {
+		start = first - maxRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + maxRunes
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	writeHighlighted(&b, runes, lower, keys, start, end)
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// HighlightAll 用 <em></em> 包裹完整文本（如标题）中所有命中的关键词，不截取片段也不去除标记（内容会先做 HTML 转义）
+func HighlightAll(content string, terms []string) string {
+	runes := []rune(content)
+	lower, keys := prepareHighlight(runes, terms)
+
+	var b strings.Builder
+	writeHighlighted(&b, runes, lower, keys, 0, len(runes))
+	return b.String()
+}
+
+// prepareHighlight 将文本和关键词统一为小写，并过滤空关键词
+func prepareHighlight(runes []rune, terms []string) ([]rune, [][]rune) {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var keys [][]rune
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			key := []rune(term)
+			for i, r := range key {
+				key[i] = unicode.ToLower(r)
+			}
+			keys = append(keys, key)
+		}
+	}
+	return lower, keys
+}
+
+// writeHighlighted 写入 runes[start:end] 并包裹其中命中的关键词
+func writeHighlighted(b *strings.Builder, runes, lower []rune, keys [][]rune, start, end int) {
+	for i := start; i < end; {
+		if n := matchAt(lower, keys, i); n > 0 {
+			if i+n > end {
+				n = end - i
+			}
+			b.WriteString("<em>")
+			b.WriteString(html.EscapeString(string(runes[i : i+n])))
+			b.WriteString("</em>")
+			i += n
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+}
+
+// indexAny 返回从 from 开始任一关键词的首个命中位置，未命中返回 -1
+func indexAny(s []rune, keys [][]rune, from int) int {
+	for i := from; i < len(s); i++ {
+		if matchAt(s, keys, i) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchAt 返回在位置 i 命中的最长关键词长度，未命中返回 0
+func matchAt(s []rune, keys [][]rune, i int) int {
+	best := 0
+	for _, key := range keys {
+		if len(key) <= best || i+len(key) > len(s) {
+			continue
+		}
+		if string(s[i:i+len(key)]) == string(key) {
+			best = len(key)
+		}
+	}
+	return best
+}